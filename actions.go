@@ -6,6 +6,7 @@ package shift
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -19,10 +20,36 @@ const (
 	CtxStats = ctxKey("stats")
 )
 
-// Run executes the given func with circuit breaker
+// Run executes the given func with circuit breaker, it falls back to the
+// default fallback on errors if the fallback option is set. The panics of the
+// operator are counted as failures and re-raised on the caller goroutine
+// without the fallback.
 func (s *Shift) Run(ctx context.Context, o Operator) (interface{}, error) {
+	return s.RunWithFallback(ctx, o, s.fallback)
+}
+
+// RunWithFallback executes the given func with circuit breaker and falls back
+// to the given fallback on errors
+func (s *Shift) RunWithFallback(ctx context.Context, o Operator, f Fallback) (interface{}, error) {
+	if !s.enter() {
+		return nil, &ShutdownError{Name: s.name}
+	}
+	defer s.leave()
+
 	ctx = context.WithValue(ctx, CtxState, s.currentState())
-	return s.runWithCallbacks(ctx, o)
+	res, err := s.runWithCallbacks(s.withTally(ctx), o, f != nil)
+
+	// Re-panic on the caller goroutine after counting the panic as a failure,
+	// so the caller can recover
+	var p *OperatorPanicError
+	if errors.As(err, &p) {
+		panic(p.Value)
+	}
+
+	if err == nil || f == nil {
+		return res, err
+	}
+	return f.Execute(ctx, err)
 }
 
 // Trip to desired state
@@ -34,15 +61,38 @@ func (s *Shift) Trip(to State, reasons ...error) error {
 		return err
 	}
 
+	// The counts are of the previous state, the ramp percentage is of the new
+	// state, so the handlers see the first ramp stage on half-open state
+	stats.RampPercent = s.stateRampPercent(to)
 	s.runStateChangeCallbacks(from, to, stats)
 	return nil
 }
 
+// SetTripPolicy swaps the trip policy at runtime
+func (s *Shift) SetTripPolicy(p TripPolicy) error {
+	if p == nil {
+		return &InvalidOptionError{
+			Name:    "trip policy",
+			Message: "can't be nil",
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tripPolicy = p
+	return nil
+}
+
 func (s *Shift) trip(to State, reasons ...error) (State, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	state := s.state
+	if s.shutdown {
+		return state, &ShutdownError{Name: s.name}
+	}
+
 	if state == to {
 		return state, &IsAlreadyInDesiredStateError{
 			Name:  s.name,
@@ -72,21 +122,28 @@ func (s *Shift) trip(to State, reasons ...error) (State, error) {
 func (s *Shift) close() {
 	// Set state
 	s.state = StateClose
+	s.transitionedAt = s.clock.Now()
 
 	// Reset timer
 	s.resetTimer.Reset()
 
 	// Reset counter
 	s.counter.Reset()
+	s.streak.reset()
 }
 
 // HalfOpen the circuit breaker
 func (s *Shift) halfOpen() {
 	// Set state
 	s.state = StateHalfOpen
+	s.transitionedAt = s.clock.Now()
+
+	// Restart the ramp
+	s.ramp.start(s.clock.Now())
 
 	// Reset counter
 	s.counter.Reset()
+	s.streak.reset()
 }
 
 // Open the circuit breaker
@@ -98,33 +155,105 @@ func (s *Shift) open(reason error) {
 	// active timer
 	s.resetter.Stop()
 
+	// Identify the opening for the recovery
+	s.openings++
+	opening := s.openings
+
 	// Reset the resetter
-	s.resetter = time.AfterFunc(duration, func() {
-		_ = s.Trip(StateHalfOpen)
+	s.resetter = s.clock.AfterFunc(duration, func() {
+		s.recover(opening)
 	})
 
 	// Set state
 	s.state = StateOpen
+	s.transitionedAt = s.clock.Now()
+	s.resetAt = s.transitionedAt.Add(duration)
 
 	// Reset counter
 	s.counter.Reset()
+	s.streak.reset()
 }
 
 /* stats */
 
 // stats returns the stats for invocations
 func (s *Shift) stats() Stats {
-	stats := s.counter.Stats(
-		metricSuccess,
-		metricFailure,
-		metricTimeout,
-		metricReject,
-	)
-	return newStats(stats)
+	metrics := builtInMetrics
+	customs := s.customOutcomes.load()
+	if len(customs) > 0 {
+		metrics = make([]string, 0, len(builtInMetrics)+len(customs))
+		metrics = append(metrics, builtInMetrics...)
+		for _, o := range customs {
+			metrics = append(metrics, customMetric(o))
+		}
+	}
+	stats := s.counter.Stats(metrics...)
+
+	res := newStats(stats)
+	if len(customs) > 0 {
+		counts := make(map[Outcome]uint32, len(customs))
+		for _, o := range customs {
+			counts[o] = stats[customMetric(o)]
+		}
+		res.custom = &customCounts{counts: counts}
+	}
+	res.ConsecutiveSuccesses, res.ConsecutiveFailures = s.streak.load()
+	res.RampPercent = s.rampPercent()
+	if c, ok := s.counter.(LatencyCounter); ok {
+		res.Latency = c.Latencies()
+	}
+	return res
+}
+
+// invocationCounts returns the successful and failed invocation counts
+// excluding the rejections
+func (s *Shift) invocationCounts() (uint32, uint32) {
+	stats := s.counter.Stats(metricSuccess, metricFailure, metricReject)
+
+	failures := stats[metricFailure]
+	if rejects := stats[metricReject]; rejects < failures {
+		failures -= rejects
+	} else {
+		failures = 0
+	}
+	return stats[metricSuccess], failures
+}
+
+// rampPercent returns the admitted percentage of the invocations
+func (s *Shift) rampPercent() float64 {
+	return s.stateRampPercent(s.currentState())
+}
+
+// stateRampPercent returns the admitted percentage of the invocations on the
+// given state
+func (s *Shift) stateRampPercent(state State) float64 {
+	switch state {
+	case StateOpen:
+		return 0.0
+	case StateHalfOpen:
+		return s.ramp.percent(s.clock.Now())
+	default:
+		return 100.0
+	}
 }
 
 /* instance accessors */
 
+// Name returns the name of the circuit breaker
+func (s *Shift) Name() string {
+	return s.name
+}
+
+// State returns the current state of the circuit breaker
+func (s *Shift) State() State {
+	return s.currentState()
+}
+
+// Stats returns the stats for invocations on the current state
+func (s *Shift) Stats() Stats {
+	return s.stats()
+}
+
 // currentState returns current state of the circuit breaker
 func (s *Shift) currentState() State {
 	s.mutex.RLock()
@@ -133,70 +262,264 @@ func (s *Shift) currentState() State {
 	return s.state
 }
 
+// currentTripPolicy returns current trip policy of the circuit breaker
+func (s *Shift) currentTripPolicy() TripPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.tripPolicy
+}
+
 /* runners */
 
-func (s *Shift) runWithCallbacks(ctx context.Context, o Operator) (interface{}, error) {
-	res, err := s.run(ctx, o)
+func (s *Shift) runWithCallbacks(ctx context.Context, o Operator, fallback bool) (interface{}, error) {
+	res, outcome, err := s.run(ctx, o)
+
+	switch outcome {
+	case OutcomeSuccess:
+		s.streak.success()
+		s.count(ctx, metricSuccess)
+	case OutcomeFailure:
+		s.streak.failure()
+		s.count(ctx, metricFailure)
+	case outcomeRejected:
+		s.reject(ctx)
+		s.count(ctx, metricFailure)
+		outcome = OutcomeFailure
+	case OutcomeIgnored:
+	default:
+		s.customOutcomes.track(outcome)
+		s.count(ctx, customMetric(outcome))
+	}
+
+	// The fallbacks run for the failed invocations except the panics
+	if _, ok := err.(*OperatorPanicError); err != nil && fallback && !ok {
+		s.count(ctx, metricFallback)
+	}
+	s.flush(ctx)
 
 	// Wrap the error with additional circuit breaker name information
 	if err != nil {
 		err = &InvocationError{Name: s.name, Err: err}
-		s.runFailureCallbacks(ctx, err)
-	} else {
+	}
+
+	switch outcome {
+	case OutcomeSuccess, OutcomeFailure:
+	case OutcomeIgnored:
+		return res, err
+	default:
+		s.runCustomOutcomeCallbacks(ctx, outcome, err)
+		return res, err
+	}
+
+	state := ctx.Value(CtxState).(State)
+	if !state.isOpen() {
+		stats := s.stats()
+		ctx = context.WithValue(ctx, CtxStats, stats)
+		s.evaluate(state, stats, outcome, err)
+	}
+
+	if outcome == OutcomeSuccess {
 		s.runSuccessCallbacks(ctx, res)
+	} else {
+		s.runFailureCallbacks(ctx, err)
 	}
 
 	return res, err
 }
 
-func (s *Shift) run(ctx context.Context, o Operator) (interface{}, error) {
+// evaluate trips the circuit breaker to the target state of the trip policy
+func (s *Shift) evaluate(state State, stats Stats, outcome Outcome, err error) {
+	to, reason := decide(s.currentTripPolicy(), state, stats, outcome)
+
+	// Skip the evaluations of the stale states
+	if to == state || s.currentState() != state {
+		return
+	}
+
+	// Keep the half-open state until the ramp completes
+	if to.isClose() && !s.ramp.completed(s.clock.Now()) {
+		return
+	}
+
+	if !to.isOpen() {
+		_ = s.Trip(to)
+		return
+	}
+
+	// Label the trip when the policy doesn't explain it
+	if reason == nil {
+		if outcome == OutcomeFailure {
+			reason = &FailureThresholdReachedError{Err: err}
+		} else {
+			reason = &PolicyTrippedError{Outcome: outcome}
+		}
+	}
+	_ = s.Trip(to, reason)
+}
+
+// classify labels the invocation error with an outcome
+func (s *Shift) classify(ctx context.Context, err error) Outcome {
+	if err == nil {
+		return OutcomeSuccess
+	}
+
+	// The panics are always failures regardless of the classifier
+	if _, ok := err.(*OperatorPanicError); ok {
+		return OutcomeFailure
+	}
+
+	if s.classifier == nil {
+		return OutcomeFailure
+	}
+
+	outcome := s.classifier.Classify(ctx, err)
+	if outcome == "" {
+		return OutcomeFailure
+	}
+	return outcome
+}
+
+func (s *Shift) run(ctx context.Context, o Operator) (interface{}, Outcome, error) {
 	for _, r := range s.restrictors {
-		defer r.Defer()
-		if ok, err := r.Check(ctx); !ok {
-			s.counter.Increment(metricReject)
-			return nil, err
+		ok, err := r.Check(ctx)
+		if pr, hasPermit := r.(PermitRestrictor); !hasPermit {
+			defer r.Defer()
+		} else if ok {
+			defer pr.Release()
+		}
+
+		if !ok {
+			s.count(ctx, metricReject)
+			return nil, outcomeRejected, err
 		}
 	}
 
+	// Rejections on open state are always failures regardless of the classifier
 	state := ctx.Value(CtxState).(State)
-	return s.invokers[state].invoke(ctx, o)
+	if state.isOpen() {
+		res, err := s.invokers[state].invoke(ctx, o)
+		return res, outcomeRejected, err
+	}
+
+	if state.isHalfOpen() {
+		if ok, percent := s.ramp.admit(s.clock.Now()); !ok {
+			s.count(ctx, metricReject)
+			return nil, outcomeRejected, &HalfOpenRampRejectedError{Percent: percent}
+		}
+
+		if !s.probes.acquire() {
+			s.count(ctx, metricReject)
+			return nil, outcomeRejected, &HalfOpenProbesExceededError{MaxProbes: s.probes.max}
+		}
+		defer s.probes.release()
+	}
+
+	start := s.clock.Now()
+	res, err := s.invokers[state].invoke(ctx, o)
+	latency := s.clock.Since(start)
+
+	outcome := s.classify(ctx, err)
+	s.report(latency, outcome, err)
+	s.record(ctx, latency, outcome, err)
+
+	return res, outcome, err
+}
+
+// record passes the invocation latency to the latency counters, only the
+// successes, failures and timeouts are recorded
+func (s *Shift) record(ctx context.Context, latency time.Duration, outcome Outcome, err error) {
+	if outcome != OutcomeSuccess && outcome != OutcomeFailure && !isTimeout(err) {
+		return
+	}
+
+	if t, ok := ctx.Value(tallyKey{}).(*tally); ok {
+		t.latency, t.timed = latency, true
+		return
+	}
+
+	if c, ok := s.counter.(LatencyCounter); ok {
+		c.Record(latency)
+	}
+}
+
+// isTimeout checks if the invocation error is a timeout, the cancellations by
+// the callers are not timeouts
+func isTimeout(err error) bool {
+	e, ok := err.(*InvocationTimeoutError)
+	return ok && e.Err == nil
+}
+
+// report passes the invocation outcome to the reporting restrictors, only the
+// successes and failures are reported
+func (s *Shift) report(latency time.Duration, outcome Outcome, err error) {
+	if outcome != OutcomeSuccess && outcome != OutcomeFailure {
+		return
+	}
+
+	for _, r := range s.restrictors {
+		if rr, ok := r.(ReportingRestrictor); ok {
+			rr.Report(latency, err)
+		}
+	}
 }
 
 /* callbacks */
 
 func (s *Shift) runSuccessCallbacks(ctx context.Context, res interface{}) {
-	s.counter.Increment(metricSuccess)
-
 	state := ctx.Value(CtxState).(State)
 	handlers := s.successHandlers[state]
 	if len(handlers) == 0 {
 		return
 	}
 
-	ctx = context.WithValue(ctx, CtxStats, s.stats())
+	if _, ok := ctx.Value(CtxStats).(Stats); !ok {
+		ctx = context.WithValue(ctx, CtxStats, s.stats())
+	}
 	for _, h := range handlers {
 		h.Handle(ctx, res)
 	}
 }
 
 func (s *Shift) runFailureCallbacks(ctx context.Context, err error) {
-	s.counter.Increment(metricFailure)
-
 	state := ctx.Value(CtxState).(State)
 	handlers := s.failureHandlers[state]
 	if len(handlers) == 0 {
 		return
 	}
 
-	ctx = context.WithValue(ctx, CtxStats, s.stats())
+	if _, ok := ctx.Value(CtxStats).(Stats); !ok {
+		ctx = context.WithValue(ctx, CtxStats, s.stats())
+	}
 	for _, h := range handlers {
 		h.Handle(ctx, err)
 	}
 }
 
-func (s *Shift) runStateChangeCallbacks(from, to State, stats Stats) {
-	handlers := s.stateChangeHandlers
+func (s *Shift) runCustomOutcomeCallbacks(ctx context.Context, outcome Outcome, err error) {
+	state := ctx.Value(CtxState).(State)
+	handlers := s.customOutcomeHandlers[state]
+	if len(handlers) == 0 {
+		return
+	}
+
+	ctx = context.WithValue(ctx, CtxStats, s.stats())
 	for _, h := range handlers {
+		h.Handle(ctx, outcome, err)
+	}
+}
+
+func (s *Shift) runLatePanicCallbacks(value interface{}) {
+	for _, h := range s.latePanicHandlers {
+		h.Handle(value)
+	}
+}
+
+func (s *Shift) runStateChangeCallbacks(from, to State, stats Stats) {
+	for _, h := range s.stateChangeHandlers {
+		h.Handle(from, to, stats)
+	}
+	for _, h := range s.hookedStateChangeHandlers {
 		h.Handle(from, to, stats)
 	}
 }
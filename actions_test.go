@@ -3,15 +3,42 @@ package shift
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/mustafaturan/shift/counter"
+	"github.com/mustafaturan/shift/histogram"
 	"github.com/mustafaturan/shift/mock"
+	"github.com/mustafaturan/shift/restrictor"
+	"github.com/mustafaturan/shift/timer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+type reportingRestrictor struct {
+	reports, defers int
+	latency         time.Duration
+	err             error
+}
+
+func (r *reportingRestrictor) Check(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *reportingRestrictor) Report(latency time.Duration, err error) {
+	r.reports++
+	r.latency = latency
+	r.err = err
+}
+
+func (r *reportingRestrictor) Defer() {
+	r.defers++
+}
+
 func TestRun(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -42,7 +69,7 @@ func TestRun(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(map[string]uint32{"success": 0, "failure": 1, "rejects": 1})
 
 		counter.
@@ -144,7 +171,7 @@ func TestRun(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(map[string]uint32{})
 
 		ctx := context.Background()
@@ -158,6 +185,105 @@ func TestRun(t *testing.T) {
 		assert.Equal(t, true, called)
 	})
 
+	t.Run("invocation reports to reporting restrictors", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+		failureErr := errors.New("failed")
+		restrictor := &reportingRestrictor{}
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInitialState(StateClose),
+			WithRestrictors(restrictor),
+		)
+
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{})
+
+		ctx := context.Background()
+		var o Operate = func(context.Context) (interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			return nil, failureErr
+		}
+
+		_, err = s.Run(ctx, o)
+		assert.Error(t, err)
+		assert.Equal(t, 1, restrictor.reports)
+		assert.Equal(t, failureErr, restrictor.err)
+		assert.True(t, restrictor.latency >= 5*time.Millisecond)
+		assert.Equal(t, 1, restrictor.defers)
+	})
+
+	t.Run("ignored and custom outcomes do not report to reporting restrictors", func(t *testing.T) {
+		errNotFound := errors.New("not found")
+		errBadRequest := errors.New("bad request")
+		restrictor := &reportingRestrictor{}
+
+		s, err := New(
+			name,
+			WithRestrictors(restrictor),
+			WithErrorClassifier(ChainClassifiers(
+				MatchErrors(OutcomeIgnored, errNotFound),
+				MatchErrors("client_error", errBadRequest),
+			)),
+		)
+		require.NoError(t, err)
+
+		for _, e := range []error{errNotFound, errBadRequest} {
+			e := e
+			var o Operate = func(context.Context) (interface{}, error) {
+				return nil, e
+			}
+			_, err = s.Run(context.Background(), o)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 0, restrictor.reports)
+		assert.Equal(t, 2, restrictor.defers)
+	})
+
+	t.Run("rejected invocation does not report to reporting restrictors", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+		restrictor := &reportingRestrictor{}
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInitialState(StateOpen),
+			WithRestrictors(restrictor),
+		)
+
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricReject)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, nil
+		}
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+		assert.Equal(t, 0, restrictor.reports)
+		assert.Equal(t, 1, restrictor.defers)
+	})
+
 	t.Run("invocation successes on operate fn without success callbacks", func(t *testing.T) {
 		timer := mock.NewMockTimer(ctrl)
 		counter := mock.NewMockCounter(ctrl)
@@ -175,6 +301,11 @@ func TestRun(t *testing.T) {
 			EXPECT().
 			Increment(metricSuccess)
 
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{"success": 1})
+
 		ctx := context.Background()
 		var o Operate = func(context.Context) (interface{}, error) {
 			return "welldone2", nil
@@ -225,7 +356,7 @@ func TestRun(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(map[string]uint32{})
 
 		ctx := context.Background()
@@ -287,7 +418,7 @@ func TestRun(t *testing.T) {
 
 			counter.
 				EXPECT().
-				Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 				Return(map[string]uint32{})
 
 			ctx := context.Background()
@@ -329,7 +460,7 @@ func TestTrip(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(stats)
 
 		err = s.Trip(StateClose)
@@ -363,7 +494,7 @@ func TestTrip(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(stats)
 
 		counter.
@@ -398,7 +529,7 @@ func TestTrip(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(stats)
 
 		counter.
@@ -433,7 +564,7 @@ func TestTrip(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(stats).
 			Times(2)
 
@@ -473,7 +604,7 @@ func TestTrip(t *testing.T) {
 
 		counter.
 			EXPECT().
-			Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 			Return(stats)
 
 		err = s.Trip(StateUnknown)
@@ -481,3 +612,1147 @@ func TestTrip(t *testing.T) {
 		assert.Equal(t, false, called)
 	})
 }
+
+type retryAfterError struct {
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return "service unavailable"
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.after
+}
+
+func TestTripWithRetryAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reason := &retryAfterError{after: 42 * time.Second}
+	var o Operate = func(context.Context) (interface{}, error) {
+		return nil, reason
+	}
+
+	t.Run("with custom failure handler", func(t *testing.T) {
+		// the fallback timer must not be called when the reason has a hint
+		fallback := mock.NewMockTimer(ctrl)
+		resetTimer, err := timer.NewRetryAfterTimer(time.Second, time.Minute, fallback)
+		require.NoError(t, err)
+
+		var s *Shift
+		var handler OnFailure = func(_ context.Context, err error) {
+			assert.NoError(t, s.Trip(StateOpen, err))
+		}
+
+		s, err = New(
+			name,
+			WithResetTimer(resetTimer),
+			WithFailureHandlers(StateClose, handler),
+		)
+		require.NoError(t, err)
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+		assert.Equal(t, StateOpen, s.currentState())
+		assert.True(t, s.resetter.Stop())
+	})
+
+	t.Run("with opener", func(t *testing.T) {
+		fallback := mock.NewMockTimer(ctrl)
+		resetTimer, err := timer.NewRetryAfterTimer(time.Second, time.Minute, fallback)
+		require.NoError(t, err)
+
+		s, err := New(
+			name,
+			WithResetTimer(resetTimer),
+			WithOpener(StateClose, 99.0, 1),
+		)
+		require.NoError(t, err)
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+		assert.Equal(t, StateOpen, s.currentState())
+		assert.True(t, s.resetter.Stop())
+	})
+}
+
+func TestRunWithPriority(t *testing.T) {
+	var load restrictor.LoadFunc = func(context.Context) float64 {
+		return 80.0
+	}
+	r, err := restrictor.NewPriorityRestrictor("priority", 10, 70.0, 90.0, load)
+	require.NoError(t, err)
+
+	s, err := New(name, WithRestrictors(r))
+	require.NoError(t, err)
+
+	var o Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	t.Run("sheds the sheddable invocations", func(t *testing.T) {
+		ctx := restrictor.WithPriority(context.Background(), restrictor.PrioritySheddable)
+		res, err := s.Run(ctx, o)
+
+		var sheddingErr *restrictor.LoadSheddingError
+		assert.True(t, errors.As(err, &sheddingErr))
+		assert.Equal(t, restrictor.PrioritySheddable, sheddingErr.Priority)
+		assert.Nil(t, res)
+		assert.Equal(t, uint32(1), s.stats().RejectCount)
+	})
+
+	t.Run("admits the normal invocations", func(t *testing.T) {
+		res, err := s.Run(context.Background(), o)
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+	})
+
+	t.Run("sheds by the failure ratio of the stats", func(t *testing.T) {
+		r, err := restrictor.NewPriorityRestrictor("priority", 10, 70.0, 90.0, nil)
+		require.NoError(t, err)
+
+		var keepClosed TripPolicyFunc = func(state State, _ Stats, _ Outcome) State {
+			return state
+		}
+		s, err := New(name, WithRestrictors(r), WithTripPolicy(keepClosed))
+		require.NoError(t, err)
+
+		errFailed := errors.New("failed")
+		var fail Operate = func(context.Context) (interface{}, error) {
+			return nil, errFailed
+		}
+		sheddable := restrictor.WithPriority(context.Background(), restrictor.PrioritySheddable)
+
+		// 2 successes and 8 failures make 80% utilisation
+		for i := 0; i < 2; i++ {
+			_, err = s.Run(sheddable, o)
+			require.NoError(t, err)
+		}
+		for i := 0; i < restrictor.PriorityMinRequests-3; i++ {
+			_, _ = s.Run(sheddable, fail)
+		}
+
+		// under the min requests
+		_, err = s.Run(sheddable, fail)
+		assert.True(t, errors.Is(err, errFailed))
+
+		var sheddingErr *restrictor.LoadSheddingError
+		_, err = s.Run(sheddable, o)
+		require.True(t, errors.As(err, &sheddingErr))
+		assert.Equal(t, 80.0, sheddingErr.Utilisation)
+
+		// the rejections don't count as failures
+		_, err = s.Run(sheddable, o)
+		require.True(t, errors.As(err, &sheddingErr))
+		assert.Equal(t, 80.0, sheddingErr.Utilisation)
+
+		res, err := s.Run(context.Background(), o)
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+	})
+}
+
+func TestRunWithBulkhead(t *testing.T) {
+	permits := 2
+	r, err := restrictor.NewBulkheadRestrictor("bulkhead", int64(permits), 0, time.Second)
+	require.NoError(t, err)
+
+	var keepClosed TripPolicyFunc = func(state State, _ Stats, _ Outcome) State {
+		return state
+	}
+	s, err := New(name, WithRestrictors(r), WithTripPolicy(keepClosed))
+	require.NoError(t, err)
+
+	var current, max int64
+	var o Operate = func(context.Context) (interface{}, error) {
+		n := atomic.AddInt64(&current, 1)
+		for {
+			m := atomic.LoadInt64(&max)
+			if n <= m || atomic.CompareAndSwapInt64(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt64(&current, -1)
+		return "welldone", nil
+	}
+
+	// The rejected invocations release no permits of the admitted ones
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_, _ = s.Run(context.Background(), o)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, atomic.LoadInt64(&max), int64(permits))
+
+	// All the permits are available afterwards
+	release := make(chan struct{})
+	started := make(chan struct{}, permits)
+	var blocking Operate = func(context.Context) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "welldone", nil
+	}
+	for i := 0; i < permits; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Run(context.Background(), blocking)
+			assert.NoError(t, err)
+		}()
+		<-started
+	}
+
+	_, err = s.Run(context.Background(), o)
+	var queueErr *restrictor.QueueFullError
+	assert.True(t, errors.As(err, &queueErr))
+
+	close(release)
+	wg.Wait()
+}
+
+func TestRunWithFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("with successful invocation", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		s, err := New(name, WithCounter(counter), WithResetTimer(timer))
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricSuccess)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{"success": 1})
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return "welldone", nil
+		}
+		var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+			t.Fatal("fallback must not be called on success")
+			return nil, nil
+		}
+
+		res, err := s.RunWithFallback(context.Background(), o, fallback)
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+	})
+
+	t.Run("with failed invocation", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+		failureErr := errors.New("failed")
+
+		s, err := New(name, WithCounter(counter), WithResetTimer(timer))
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{})
+
+		counter.
+			EXPECT().
+			Increment(metricFallback)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, failureErr
+		}
+		var fallback FallbackFunc = func(ctx context.Context, err error) (interface{}, error) {
+			assert.Equal(t, StateClose, ctx.Value(CtxState))
+			assert.True(t, errors.Is(err, failureErr))
+			return "cached", nil
+		}
+
+		res, err := s.RunWithFallback(context.Background(), o, fallback)
+		assert.NoError(t, err)
+		assert.Equal(t, "cached", res)
+	})
+
+	t.Run("with rejected invocation on open state", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInitialState(StateOpen),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricReject)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		counter.
+			EXPECT().
+			Increment(metricFallback)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return "welldone", nil
+		}
+		var fallback FallbackFunc = func(_ context.Context, err error) (interface{}, error) {
+			var openErr *IsOnOpenStateError
+			assert.True(t, errors.As(err, &openErr))
+			return nil, err
+		}
+
+		res, err := s.RunWithFallback(context.Background(), o, fallback)
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("with default fallback on run", func(t *testing.T) {
+		var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+			return "cached", nil
+		}
+		s, err := New(name, WithInitialState(StateOpen), WithFallback(fallback))
+		require.NoError(t, err)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return "welldone", nil
+		}
+
+		res, err := s.Run(context.Background(), o)
+		assert.NoError(t, err)
+		assert.Equal(t, "cached", res)
+
+		stats := s.stats()
+		assert.Equal(t, uint32(1), stats.FallbackCount)
+		assert.Equal(t, uint32(1), stats.FailureCount)
+		assert.Equal(t, uint32(1), stats.RejectCount)
+	})
+}
+
+func TestRunWithPanic(t *testing.T) {
+	var failures []error
+	var onFailure OnFailure = func(_ context.Context, err error) {
+		failures = append(failures, err)
+	}
+	var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+		return "fallback", nil
+	}
+
+	s, err := New(
+		name,
+		WithOpener(StateClose, 90.0, 1),
+		WithFailureHandlers(StateClose, onFailure),
+		WithFallback(fallback),
+	)
+	require.NoError(t, err)
+
+	var o Operate = func(context.Context) (interface{}, error) {
+		panic("boom")
+	}
+
+	// The panic is counted as a failure before re-raised without the fallback
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = s.Run(context.Background(), o)
+	})
+
+	stats := s.stats()
+	assert.Equal(t, uint32(0), stats.FailureCount)
+	assert.Equal(t, uint32(0), stats.FallbackCount)
+	assert.Equal(t, StateOpen, s.currentState())
+
+	require.Len(t, failures, 1)
+	var panicErr *OperatorPanicError
+	assert.True(t, errors.As(failures[0], &panicErr))
+	assert.Equal(t, "boom", panicErr.Value)
+
+	t.Run("with an error classifier", func(t *testing.T) {
+		var classifier ClassifierFunc = func(context.Context, error) Outcome {
+			return OutcomeIgnored
+		}
+		s, err := New(name, WithOpener(StateClose, 90.0, 1), WithErrorClassifier(classifier))
+		require.NoError(t, err)
+
+		assert.Panics(t, func() { _, _ = s.Run(context.Background(), o) })
+		assert.Equal(t, StateOpen, s.currentState())
+	})
+}
+
+func TestRunWithErrorClassifier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errNotFound := errors.New("not found")
+	classifier := ChainClassifiers(
+		IgnoreContextCanceled(),
+		MatchErrors(OutcomeSuccess, errNotFound),
+		MatchErrorsAs(Outcome("client_error"), new(*notFoundError)),
+	)
+
+	t.Run("counts the matching errors as success", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		var successCalled bool
+		var onSuccess OnSuccess = func(_ context.Context, res interface{}) {
+			successCalled = true
+		}
+		var onFailure OnFailure = func(context.Context, error) {
+			t.Fatal("failure handlers must not be called")
+		}
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+			WithSuccessHandlers(StateClose, onSuccess),
+			WithFailureHandlers(StateClose, onFailure),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricSuccess)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{})
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, errNotFound
+		}
+
+		res, err := s.Run(context.Background(), o)
+		assert.True(t, errors.Is(err, errNotFound))
+		assert.IsType(t, &InvocationError{}, err)
+		assert.Nil(t, res)
+		assert.True(t, successCalled)
+	})
+
+	t.Run("ignores the cancellations", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var o Operate = func(context.Context) (interface{}, error) {
+			cancel()
+			time.Sleep(2 * time.Millisecond)
+			return "welldone", nil
+		}
+
+		res, err := s.Run(ctx, o)
+		assert.True(t, errors.Is(err, context.Canceled))
+		var timeoutErr *InvocationTimeoutError
+		assert.True(t, errors.As(err, &timeoutErr))
+		assert.Nil(t, res)
+	})
+
+	t.Run("counts the custom categories", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		var handled []Outcome
+		var onCustomOutcome OnCustomOutcome = func(ctx context.Context, outcome Outcome, err error) {
+			assert.Equal(t, uint32(1), ctx.Value(CtxStats).(Stats).CustomCount(outcome))
+			assert.IsType(t, &InvocationError{}, err)
+			handled = append(handled, outcome)
+		}
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+			WithCustomOutcomeHandlers(StateClose, onCustomOutcome),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(CustomOutcomePrefix + "client_error")
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow, CustomOutcomePrefix+"client_error").
+			Return(map[string]uint32{CustomOutcomePrefix + "client_error": 1}).
+			Times(2)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, &notFoundError{}
+		}
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+		assert.Equal(t, []Outcome{"client_error"}, handled)
+		assert.Equal(t, map[Outcome]uint32{"client_error": 1}, s.Stats().CustomCounts())
+	})
+
+	t.Run("namespaces the custom categories with reserved names", func(t *testing.T) {
+		var reserved ClassifierFunc = func(_ context.Context, err error) Outcome {
+			return Outcome(err.Error())
+		}
+		s, err := New(name, WithErrorClassifier(reserved))
+		require.NoError(t, err)
+
+		for _, label := range []string{metricTimeout, metricReject, metricFallback, metricSlow} {
+			label := label
+			var o Operate = func(context.Context) (interface{}, error) {
+				return nil, errors.New(label)
+			}
+			_, err = s.Run(context.Background(), o)
+			assert.Error(t, err)
+		}
+
+		stats := s.Stats()
+		assert.Equal(t, uint32(0), stats.FailureCount)
+		assert.Equal(t, uint32(0), stats.TimeoutCount)
+		assert.Equal(t, uint32(0), stats.RejectCount)
+		assert.Equal(t, uint32(0), stats.FallbackCount)
+		assert.Equal(t, uint32(0), stats.SlowCount)
+		assert.Equal(t, map[Outcome]uint32{
+			metricTimeout:  1,
+			metricReject:   1,
+			metricFallback: 1,
+			metricSlow:     1,
+		}, stats.CustomCounts())
+	})
+
+	t.Run("counts the unmatched errors as failure", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{})
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, errors.New("failed")
+		}
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+	})
+
+	t.Run("counts the rejections as failure", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		counter := mock.NewMockCounter(ctrl)
+
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInitialState(StateOpen),
+			WithErrorClassifier(MatchErrorsAs(OutcomeIgnored, new(*IsOnOpenStateError))),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Increment(metricReject)
+
+		counter.
+			EXPECT().
+			Increment(metricFailure)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return "welldone", nil
+		}
+
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+	})
+}
+
+func TestRunWithSlowCalls(t *testing.T) {
+	s, err := New(
+		name,
+		WithSlowCallThreshold(time.Millisecond),
+		WithSlowCallOpener(StateClose, 50.0, 4),
+	)
+	require.NoError(t, err)
+
+	var fast Operate = func(context.Context) (interface{}, error) {
+		return "fast", nil
+	}
+	var slow Operate = func(context.Context) (interface{}, error) {
+		time.Sleep(2 * time.Millisecond)
+		return "slow", nil
+	}
+
+	for _, o := range []Operate{fast, slow, slow} {
+		_, err := s.Run(context.Background(), o)
+		require.NoError(t, err)
+	}
+
+	stats := s.stats()
+	assert.Equal(t, uint32(3), stats.SuccessCount)
+	assert.Equal(t, uint32(2), stats.SlowCount)
+	assert.Equal(t, StateClose, s.currentState())
+
+	// Trips to open state when the slow call ratio exceeds the threshold
+	res, err := s.Run(context.Background(), slow)
+	assert.NoError(t, err)
+	assert.Equal(t, "slow", res)
+	assert.Equal(t, StateOpen, s.currentState())
+}
+
+func TestRunWithConsecutiveFailures(t *testing.T) {
+	var load restrictor.LoadFunc = func(context.Context) float64 {
+		return 80.0
+	}
+	shedding, err := restrictor.NewPriorityRestrictor("shedding", 10, 70.0, 90.0, load)
+	require.NoError(t, err)
+
+	s, err := New(
+		name,
+		WithConsecutiveOpener(StateClose, 3, CombineAny),
+		WithRestrictors(shedding),
+	)
+	require.NoError(t, err)
+
+	var failure Operate = func(context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	ctx := context.Background()
+	_, _ = s.Run(ctx, failure)
+	_, _ = s.Run(ctx, failure)
+	_, _ = s.Run(ctx, success)
+	_, _ = s.Run(ctx, failure)
+	_, _ = s.Run(ctx, failure)
+
+	stats := s.stats()
+	assert.Equal(t, uint32(0), stats.ConsecutiveSuccesses)
+	assert.Equal(t, uint32(2), stats.ConsecutiveFailures)
+	assert.Equal(t, StateClose, s.currentState())
+
+	// Rejections do not extend the streaks
+	_, err = s.Run(restrictor.WithPriority(ctx, restrictor.PrioritySheddable), success)
+	assert.Error(t, err)
+	assert.Equal(t, uint32(2), s.stats().ConsecutiveFailures)
+	assert.Equal(t, StateClose, s.currentState())
+
+	// Trips to open state on the third failure in a row
+	_, _ = s.Run(ctx, failure)
+	assert.Equal(t, StateOpen, s.currentState())
+	assert.Equal(t, uint32(0), s.stats().ConsecutiveFailures)
+}
+
+func TestRunWithCountWindowCounter(t *testing.T) {
+	c, err := counter.NewCountWindowCounter(4)
+	require.NoError(t, err)
+
+	s, err := New(name, WithCounter(c), WithOpener(StateClose, 50.0, 4))
+	require.NoError(t, err)
+
+	var failure Operate = func(context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, _ = s.Run(ctx, success)
+	}
+	_, _ = s.Run(ctx, failure)
+	_, _ = s.Run(ctx, failure)
+
+	// The ratio is evaluated over the last 4 invocations only
+	stats := s.stats()
+	assert.Equal(t, uint32(2), stats.SuccessCount)
+	assert.Equal(t, uint32(2), stats.FailureCount)
+	assert.Equal(t, StateClose, s.currentState())
+
+	_, _ = s.Run(ctx, failure)
+	assert.Equal(t, StateOpen, s.currentState())
+}
+
+func TestRunWithLatencyCounter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("records the latencies", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		counter := mock.NewMockLatencyCounter(ctrl)
+		summary := histogram.Summary{Count: 1, Mean: 25 * time.Millisecond}
+
+		var latency histogram.Summary
+		var handler OnSuccess = func(ctx context.Context, _ interface{}) {
+			latency = ctx.Value(CtxStats).(Stats).Latency
+		}
+
+		s, err := New(
+			name,
+			WithClock(clk),
+			WithCounter(counter),
+			WithSuccessHandlers(StateClose, handler),
+		)
+		require.NoError(t, err)
+
+		counter.
+			EXPECT().
+			Record(25 * time.Millisecond)
+
+		counter.
+			EXPECT().
+			Increment(metricSuccess)
+
+		counter.
+			EXPECT().
+			Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+			Return(map[string]uint32{"success": 1})
+
+		counter.
+			EXPECT().
+			Latencies().
+			Return(summary)
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			clk.Advance(25 * time.Millisecond)
+			return "welldone", nil
+		}
+
+		res, err := s.Run(context.Background(), o)
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+		assert.Equal(t, summary, latency)
+	})
+
+	t.Run("trips on the latency percentiles", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		c, err := counter.NewTimeBucketCounter(10, time.Second, counter.WithClock(clk))
+		require.NoError(t, err)
+
+		var policy TripPolicyFunc = func(state State, stats Stats, _ Outcome) State {
+			if stats.Latency.Count >= 4 && stats.Latency.P90 > 100*time.Millisecond {
+				return StateOpen
+			}
+			return state
+		}
+
+		s, err := New(name, WithClock(clk), WithCounter(c), WithTripPolicy(policy))
+		require.NoError(t, err)
+
+		run := func(latency time.Duration) {
+			var o Operate = func(context.Context) (interface{}, error) {
+				clk.Advance(latency)
+				return "welldone", nil
+			}
+			_, err := s.Run(context.Background(), o)
+			require.NoError(t, err)
+		}
+
+		for _, latency := range []time.Duration{10, 20, 30} {
+			run(latency * time.Millisecond)
+		}
+
+		stats := s.stats()
+		assert.Equal(t, uint32(3), stats.Latency.Count)
+		assert.Equal(t, 20*time.Millisecond, stats.Latency.Mean)
+		assert.Equal(t, 30*time.Millisecond, stats.Latency.Max.Truncate(time.Millisecond))
+		assert.Equal(t, StateClose, s.currentState())
+
+		run(200 * time.Millisecond)
+		assert.Equal(t, StateOpen, s.currentState())
+		assert.Equal(t, uint32(0), s.stats().Latency.Count)
+	})
+
+	t.Run("records only the counted outcomes", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		c, err := counter.NewTimeBucketCounter(10, time.Second, counter.WithClock(clk))
+		require.NoError(t, err)
+
+		errIgnored := errors.New("ignored")
+		errCustom := errors.New("custom")
+		classifier := ChainClassifiers(
+			MatchErrors(OutcomeIgnored, errIgnored),
+			MatchErrorsAs(OutcomeIgnored, new(*InvocationTimeoutError)),
+			MatchErrors(Outcome("custom"), errCustom),
+		)
+
+		s, err := New(
+			name,
+			WithClock(clk),
+			WithCounter(c),
+			WithErrorClassifier(classifier),
+			WithInvocationTimeout(time.Second),
+		)
+		require.NoError(t, err)
+
+		run := func(latency time.Duration, err error) {
+			var o Operate = func(context.Context) (interface{}, error) {
+				clk.Advance(latency)
+				return nil, err
+			}
+			_, _ = s.Run(context.Background(), o)
+		}
+
+		run(10*time.Millisecond, nil)
+		run(20*time.Millisecond, errors.New("failed"))
+		run(30*time.Millisecond, errIgnored)
+		run(40*time.Millisecond, errCustom)
+		assert.Equal(t, uint32(2), s.stats().Latency.Count)
+		assert.Equal(t, 15*time.Millisecond, s.stats().Latency.Mean)
+
+		// the timeouts are recorded even when they are ignored
+		run(2*time.Second, errors.New("late"))
+		assert.Equal(t, uint32(1), s.stats().TimeoutCount)
+		assert.Equal(t, uint32(1), s.stats().FailureCount)
+		assert.Equal(t, uint32(3), s.stats().Latency.Count)
+	})
+}
+
+func TestRunWithInvocationCounter(t *testing.T) {
+	var keepClosed TripPolicyFunc = func(state State, _ Stats, _ Outcome) State {
+		return state
+	}
+	var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+		return "fallback", nil
+	}
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+	var failure Operate = func(context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+
+	t.Run("counts the fallbacks with their invocations", func(t *testing.T) {
+		c, err := counter.NewCountWindowCounter(1)
+		require.NoError(t, err)
+
+		s, err := New(name, WithCounter(c), WithTripPolicy(keepClosed), WithFallback(fallback))
+		require.NoError(t, err)
+
+		res, err := s.Run(context.Background(), failure)
+		assert.NoError(t, err)
+		assert.Equal(t, "fallback", res)
+		assert.Equal(t, uint32(1), s.stats().FallbackCount)
+
+		// the fallback leaves the window with its invocation
+		_, err = s.Run(context.Background(), success)
+		assert.NoError(t, err)
+		stats := s.stats()
+		assert.Equal(t, uint32(1), stats.SuccessCount)
+		assert.Equal(t, uint32(0), stats.FailureCount)
+		assert.Equal(t, uint32(0), stats.FallbackCount)
+		assert.Equal(t, uint32(1), stats.Latency.Count)
+	})
+
+	t.Run("keeps the invocations together under concurrency", func(t *testing.T) {
+		size := 16
+		c, err := counter.NewCountWindowCounter(size)
+		require.NoError(t, err)
+
+		s, err := New(name, WithCounter(c), WithTripPolicy(keepClosed), WithFallback(fallback))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					o := success
+					if (i+j)%2 == 0 {
+						o = failure
+					}
+					_, _ = s.Run(context.Background(), o)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		stats := s.stats()
+		assert.Equal(t, uint32(size), stats.SuccessCount+stats.FailureCount)
+		assert.Equal(t, stats.FailureCount, stats.FallbackCount)
+		assert.Equal(t, uint32(size), stats.Latency.Count)
+	})
+
+	t.Run("keeps the executed invocations on the rejections", func(t *testing.T) {
+		c, err := counter.NewCountWindowCounter(10)
+		require.NoError(t, err)
+
+		s, err := New(
+			name,
+			WithClock(clocktest.NewClock(time.Now())),
+			WithCounter(c),
+			WithTripPolicy(keepClosed),
+			WithInitialState(StateHalfOpen),
+			WithHalfOpenRamp(time.Minute, 50.0, 100.0),
+		)
+		require.NoError(t, err)
+
+		var random float64
+		s.ramp.random = func() float64 { return random }
+
+		// every other invocation is rejected by the ramp
+		for i := 0; i < 40; i++ {
+			o := success
+			if i%4 == 2 {
+				o = failure
+			}
+
+			random = 0.0
+			if i%2 == 1 {
+				random = 0.99
+			}
+			_, _ = s.Run(context.Background(), o)
+		}
+
+		stats := s.stats()
+		assert.Equal(t, uint32(5), stats.SuccessCount)
+		assert.Equal(t, uint32(15), stats.FailureCount)
+		assert.Equal(t, uint32(10), stats.RejectCount)
+		assert.Equal(t, uint32(10), stats.Latency.Count)
+
+		successes, failures := s.invocationCounts()
+		assert.Equal(t, uint32(5), successes)
+		assert.Equal(t, uint32(5), failures)
+	})
+}
+
+func TestSetTripPolicy(t *testing.T) {
+	s, err := New(name)
+	require.NoError(t, err)
+
+	t.Run("with a nil trip policy", func(t *testing.T) {
+		err := s.SetTripPolicy(nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with a trip policy", func(t *testing.T) {
+		policy, err := NewConsecutiveOpenPolicy(StateClose, 1)
+		require.NoError(t, err)
+		require.NoError(t, s.SetTripPolicy(policy))
+
+		var o Operate = func(context.Context) (interface{}, error) {
+			return nil, errors.New("failed")
+		}
+
+		// Trips to open state on the first failure with the swapped policy
+		_, err = s.Run(context.Background(), o)
+		assert.Error(t, err)
+		assert.Equal(t, StateOpen, s.currentState())
+	})
+}
+
+func TestRunWithHalfOpenMaxProbes(t *testing.T) {
+	s, err := New(
+		name,
+		WithInitialState(StateHalfOpen),
+		WithHalfOpenMaxProbes(1),
+	)
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var probe Operate = func(context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return "probe", nil
+	}
+	var o Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err := s.Run(context.Background(), probe)
+		assert.NoError(t, err)
+		assert.Equal(t, "probe", res)
+	}()
+	<-started
+
+	// Rejects the invocations above the max probes
+	res, err := s.Run(context.Background(), o)
+	var probesErr *HalfOpenProbesExceededError
+	assert.True(t, errors.As(err, &probesErr))
+	assert.Equal(t, int64(1), probesErr.MaxProbes)
+	assert.Nil(t, res)
+
+	stats := s.stats()
+	assert.Equal(t, uint32(1), stats.RejectCount)
+	assert.Equal(t, uint32(1), stats.FailureCount)
+	assert.Equal(t, uint32(0), stats.ConsecutiveFailures)
+
+	close(release)
+	<-done
+
+	// Admits the invocations after the probe completes
+	res, err = s.Run(context.Background(), o)
+	assert.NoError(t, err)
+	assert.Equal(t, "welldone", res)
+	assert.Equal(t, StateHalfOpen, s.currentState())
+}
+
+func TestRunWithHalfOpenRamp(t *testing.T) {
+	s, err := New(
+		name,
+		WithInitialState(StateHalfOpen),
+		WithHalfOpenRamp(time.Minute, 10.0, 100.0),
+		WithConsecutiveCloser(1, CombineAny),
+	)
+	require.NoError(t, err)
+
+	random := 0.5
+	s.ramp.random = func() float64 { return random }
+
+	var o Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	t.Run("rejects the invocations above the percentage", func(t *testing.T) {
+		res, err := s.Run(context.Background(), o)
+
+		var rampErr *HalfOpenRampRejectedError
+		assert.True(t, errors.As(err, &rampErr))
+		assert.Equal(t, 10.0, rampErr.Percent)
+		assert.Nil(t, res)
+
+		stats := s.stats()
+		assert.Equal(t, uint32(1), stats.RejectCount)
+		assert.Equal(t, 10.0, stats.RampPercent)
+	})
+
+	t.Run("keeps half-open state until the ramp completes", func(t *testing.T) {
+		random = 0.05
+		res, err := s.Run(context.Background(), o)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+		assert.Equal(t, StateHalfOpen, s.currentState())
+	})
+
+	t.Run("trips to close state after the ramp completes", func(t *testing.T) {
+		var rampPercent float64
+		s.stateChangeHandlers = append(s.stateChangeHandlers, OnStateChange(func(_, _ State, stats Stats) {
+			rampPercent = stats.RampPercent
+		}))
+
+		random = 0.5
+		s.ramp.start(time.Now().Add(-time.Minute))
+		res, err := s.Run(context.Background(), o)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+		assert.Equal(t, StateClose, s.currentState())
+		assert.Equal(t, 100.0, rampPercent)
+		assert.Equal(t, 100.0, s.stats().RampPercent)
+	})
+
+	t.Run("reports the ramp percentage of the new state on state changes", func(t *testing.T) {
+		var percents []float64
+		var handler OnStateChange = func(_, _ State, stats Stats) {
+			percents = append(percents, stats.RampPercent)
+		}
+
+		s, err := New(
+			name,
+			WithInitialState(StateOpen),
+			WithHalfOpenRamp(time.Minute, 10.0, 100.0),
+			WithStateChangeHandlers(handler),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Trip(StateHalfOpen))
+		require.NoError(t, s.Trip(StateOpen))
+		require.NoError(t, s.Trip(StateClose))
+		assert.Equal(t, []float64{10.0, 0.0, 100.0}, percents)
+	})
+}
+
+func TestAccessors(t *testing.T) {
+	s, err := New(name, WithInitialState(StateHalfOpen))
+	require.NoError(t, err)
+
+	var o Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+	_, err = s.Run(context.Background(), o)
+	require.NoError(t, err)
+
+	assert.Equal(t, name, s.Name())
+	assert.Equal(t, StateHalfOpen, s.State())
+	assert.Equal(t, uint32(1), s.Stats().SuccessCount)
+	assert.Equal(t, uint32(1), s.Stats().ConsecutiveSuccesses)
+}
+
+func BenchmarkRun(b *testing.B) {
+	bucketed, _ := counter.NewTimeBucketCounter(10, time.Second)
+	sliding, _ := counter.NewSlidingWindowCounter(10, time.Second, counter.DefaultMetrics)
+	counted, _ := counter.NewCountWindowCounter(100)
+
+	counters := []struct {
+		name    string
+		counter Counter
+	}{
+		{"TimeBucketCounter", bucketed},
+		{"SlidingWindowCounter", sliding},
+		{"CountWindowCounter", counted},
+	}
+
+	var fn Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	for _, c := range counters {
+		b.Run(c.name, func(b *testing.B) {
+			s, err := New(name, WithCounter(c.counter))
+			require.NoError(b, err)
+			defer func() { _ = s.Shutdown(context.Background()) }()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, _ = s.Run(context.Background(), fn)
+				}
+			})
+		})
+	}
+}
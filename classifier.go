@@ -0,0 +1,155 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Outcome is a label for invocation outcomes, any value other than the
+// built-in outcomes is a custom category which is counted with its own metric
+// name prefixed by CustomOutcomePrefix on the counter and runs the custom
+// outcome handlers instead of the success and failure handlers
+type Outcome string
+
+const (
+	// OutcomeSuccess counts the invocation as success and runs the success
+	// handlers
+	OutcomeSuccess Outcome = metricSuccess
+
+	// OutcomeFailure counts the invocation as failure and runs the failure
+	// handlers
+	OutcomeFailure Outcome = metricFailure
+
+	// OutcomeIgnored does not count the invocation at all and does not run the
+	// success and failure handlers
+	OutcomeIgnored Outcome = "ignored"
+
+	// outcomeRejected counts the invocation as failure without breaking the
+	// streaks, the rejections are not classified. It is the empty outcome
+	// which the classifiers can't return.
+	outcomeRejected Outcome = ""
+)
+
+// ErrorClassifier is an interface to label invocation errors with outcomes
+// before the counters and the handlers run
+type ErrorClassifier interface {
+	Classify(context.Context, error) Outcome
+}
+
+// ClassifierFunc is a function to label invocation errors with outcomes
+type ClassifierFunc func(context.Context, error) Outcome
+
+// Classify implements ErrorClassifier for ClassifierFunc func
+func (fn ClassifierFunc) Classify(ctx context.Context, err error) Outcome {
+	return fn(ctx, err)
+}
+
+// IgnoreContextCanceled builds a classifier which ignores the invocations
+// cancelled by the callers like client disconnects
+func IgnoreContextCanceled() ErrorClassifier {
+	return MatchErrors(OutcomeIgnored, context.Canceled)
+}
+
+// MatchErrors builds a classifier which labels the errors matching any of the
+// targets using errors.Is with the given outcome and the others as failure
+func MatchErrors(outcome Outcome, targets ...error) ErrorClassifier {
+	var fn ClassifierFunc = func(_ context.Context, err error) Outcome {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return outcome
+			}
+		}
+		return OutcomeFailure
+	}
+	return fn
+}
+
+// MatchErrorsAs builds a classifier which labels the errors matching any of the
+// targets using errors.As with the given outcome and the others as failure.
+// Same as errors.As, the targets must be non-nil pointers to either a type that
+// implements error, or to any interface type. The targets are only used as type
+// information, so they are never assigned.
+func MatchErrorsAs(outcome Outcome, targets ...interface{}) ErrorClassifier {
+	types := make([]reflect.Type, len(targets))
+	for i, target := range targets {
+		types[i] = reflect.TypeOf(target).Elem()
+	}
+
+	var fn ClassifierFunc = func(_ context.Context, err error) Outcome {
+		for _, typ := range types {
+			if errors.As(err, reflect.New(typ).Interface()) {
+				return outcome
+			}
+		}
+		return OutcomeFailure
+	}
+	return fn
+}
+
+// ChainClassifiers builds a classifier which returns the first outcome other
+// than failure from the given classifiers in order
+func ChainClassifiers(classifiers ...ErrorClassifier) ErrorClassifier {
+	var fn ClassifierFunc = func(ctx context.Context, err error) Outcome {
+		for _, c := range classifiers {
+			if outcome := c.Classify(ctx, err); outcome != OutcomeFailure {
+				return outcome
+			}
+		}
+		return OutcomeFailure
+	}
+	return fn
+}
+
+// customOutcomes tracks the custom outcomes seen by the circuit breaker to
+// report their counts in the stats, the custom outcomes are expected to be a
+// small set of labels
+type customOutcomes struct {
+	mutex    sync.Mutex
+	outcomes atomic.Value
+}
+
+// track adds the given outcome to the tracked outcomes once
+func (c *customOutcomes) track(outcome Outcome) {
+	if c.contains(outcome) {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.contains(outcome) {
+		return
+	}
+
+	// Copy on write to keep the reads lock-free
+	current := c.load()
+	outcomes := make([]Outcome, len(current), len(current)+1)
+	copy(outcomes, current)
+	c.outcomes.Store(append(outcomes, outcome))
+}
+
+// load returns the tracked outcomes in the order of their first occurrences
+func (c *customOutcomes) load() []Outcome {
+	outcomes, _ := c.outcomes.Load().([]Outcome)
+	return outcomes
+}
+
+func (c *customOutcomes) contains(outcome Outcome) bool {
+	for _, o := range c.load() {
+		if o == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// customMetric returns the counter metric of the custom outcome
+func customMetric(outcome Outcome) string {
+	return CustomOutcomePrefix + string(outcome)
+}
@@ -0,0 +1,65 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type notFoundError struct{}
+
+func (e *notFoundError) Error() string {
+	return "not found"
+}
+
+func TestClassifierFunc_Classify(t *testing.T) {
+	var fn ClassifierFunc = func(context.Context, error) Outcome {
+		return OutcomeIgnored
+	}
+	assert.Equal(t, OutcomeIgnored, fn.Classify(context.Background(), errors.New("err")))
+}
+
+func TestIgnoreContextCanceled(t *testing.T) {
+	c := IgnoreContextCanceled()
+	ctx := context.Background()
+
+	assert.Equal(t, OutcomeIgnored, c.Classify(ctx, context.Canceled))
+	assert.Equal(t, OutcomeIgnored, c.Classify(ctx, fmt.Errorf("wrapped: %w", context.Canceled)))
+	assert.Equal(t, OutcomeFailure, c.Classify(ctx, context.DeadlineExceeded))
+	assert.Equal(t, OutcomeFailure, c.Classify(ctx, errors.New("err")))
+}
+
+func TestMatchErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	c := MatchErrors(OutcomeSuccess, errNotFound)
+	ctx := context.Background()
+
+	assert.Equal(t, OutcomeSuccess, c.Classify(ctx, errNotFound))
+	assert.Equal(t, OutcomeSuccess, c.Classify(ctx, fmt.Errorf("wrapped: %w", errNotFound)))
+	assert.Equal(t, OutcomeFailure, c.Classify(ctx, errors.New("not found")))
+}
+
+func TestMatchErrorsAs(t *testing.T) {
+	c := MatchErrorsAs(Outcome("client_error"), new(*notFoundError))
+	ctx := context.Background()
+
+	assert.Equal(t, Outcome("client_error"), c.Classify(ctx, &notFoundError{}))
+	assert.Equal(t, Outcome("client_error"), c.Classify(ctx, fmt.Errorf("wrapped: %w", &notFoundError{})))
+	assert.Equal(t, OutcomeFailure, c.Classify(ctx, errors.New("not found")))
+}
+
+func TestChainClassifiers(t *testing.T) {
+	errNotFound := errors.New("not found")
+	c := ChainClassifiers(
+		IgnoreContextCanceled(),
+		MatchErrors(OutcomeSuccess, errNotFound),
+	)
+	ctx := context.Background()
+
+	assert.Equal(t, OutcomeIgnored, c.Classify(ctx, context.Canceled))
+	assert.Equal(t, OutcomeSuccess, c.Classify(ctx, errNotFound))
+	assert.Equal(t, OutcomeFailure, c.Classify(ctx, errors.New("err")))
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+// Package clock abstracts the time source of the circuit breaker and its
+// components, so the time based behaviours can be driven deterministically
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is an interface for the time source
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Since returns the time elapsed since the given time
+	Since(t time.Time) time.Duration
+
+	// AfterFunc calls the given func in its own goroutine after the duration
+	AfterFunc(d time.Duration, f func()) Timer
+
+	// NewTimer returns a timer which sends the current time on its chan after
+	// the duration
+	NewTimer(d time.Duration) Timer
+
+	// WithTimeout returns a copy of the parent context which is cancelled with
+	// context.DeadlineExceeded error after the duration
+	WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc)
+}
+
+// Timer is an interface for the single events of the clock
+type Timer interface {
+	// C returns the chan which receives the time when the timer fires, it is
+	// nil for the timers created with AfterFunc
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing, it returns false if the timer has
+	// already fired or been stopped
+	Stop() bool
+
+	// Reset changes the timer to fire after the duration, it returns true if
+	// the timer had been active
+	Reset(d time.Duration) bool
+}
+
+// SystemClock is a clock backed by the time package
+type SystemClock struct{}
+
+// NewSystemClock inits a clock backed by the time package
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
+
+// Now returns the current local time
+func (c *SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since the given time, it only reads the
+// monotonic clock for the times returned by Now
+func (c *SystemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// AfterFunc waits for the duration to elapse and then calls the given func in
+// its own goroutine
+func (c *SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return &systemTimer{timer: time.AfterFunc(d, f)}
+}
+
+// NewTimer creates a new timer which sends the current time on its chan after
+// the duration
+func (c *SystemClock) NewTimer(d time.Duration) Timer {
+	return &systemTimer{timer: time.NewTimer(d)}
+}
+
+// WithTimeout returns context.WithTimeout(ctx, d)
+func (c *SystemClock) WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d)
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t *systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *systemTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
@@ -0,0 +1,51 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	c := NewSystemClock()
+
+	t.Run("Now", func(t *testing.T) {
+		before := time.Now()
+		now := c.Now()
+
+		assert.False(t, now.Before(before))
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		start := c.Now()
+
+		assert.True(t, c.Since(start) >= 0)
+	})
+
+	t.Run("AfterFunc", func(t *testing.T) {
+		fired := make(chan struct{})
+		timer := c.AfterFunc(time.Millisecond, func() { close(fired) })
+
+		<-fired
+		assert.Nil(t, timer.C())
+		assert.False(t, timer.Stop())
+	})
+
+	t.Run("NewTimer", func(t *testing.T) {
+		timer := c.NewTimer(time.Hour)
+		assert.True(t, timer.Stop())
+
+		assert.False(t, timer.Reset(time.Millisecond))
+		<-timer.C()
+	})
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		ctx, cancel := c.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+// Package clocktest provides a manual clock to drive the time based behaviours
+// of the circuit breaker and its components in tests
+package clocktest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+)
+
+// Clock is a manual clock which only moves with Advance and Set calls, the due
+// timers fire in the order of their deadlines on the goroutine moving the clock
+type Clock struct {
+	mutex sync.Mutex
+
+	now    time.Time
+	timers []*timer
+}
+
+var _ clock.Clock = (*Clock)(nil)
+
+// NewClock inits a manual clock at the given time
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Since returns the time elapsed since the given time on the clock
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Advance moves the clock forward by the given duration and fires the due
+// timers
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to the given time and fires the due timers, the clock
+// never moves backward
+func (c *Clock) Set(to time.Time) {
+	for {
+		c.mutex.Lock()
+		t := c.next(to)
+		if t == nil {
+			if to.After(c.now) {
+				c.now = to
+			}
+			c.mutex.Unlock()
+			return
+		}
+
+		// Move the clock to the deadline of the timer, so the timers scheduled
+		// by the fired timers fire in order too
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		c.remove(t)
+		now := c.now
+		c.mutex.Unlock()
+
+		t.fire(now)
+	}
+}
+
+// Timers returns the number of active timers, it helps to wait for the
+// goroutines to schedule their timers before moving the clock
+func (c *Clock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.timers)
+}
+
+// AfterFunc calls the given func after the clock advances by the duration
+func (c *Clock) AfterFunc(d time.Duration, f func()) clock.Timer {
+	return c.schedule(d, &timer{clock: c, f: f})
+}
+
+// NewTimer returns a timer which sends the time on its chan after the clock
+// advances by the duration
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	return c.schedule(d, &timer{clock: c, ch: make(chan time.Time, 1)})
+}
+
+// WithTimeout returns a copy of the parent context which is cancelled with
+// context.DeadlineExceeded error after the clock advances by the duration, the
+// deadline is the parent's one when it is earlier
+func (c *Clock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	deadline := c.Now().Add(d)
+	if cur, ok := parent.Deadline(); ok && cur.Before(deadline) {
+		deadline = cur
+	}
+
+	inner, cancel := context.WithCancel(parent)
+	ctx := &timeoutCtx{Context: inner, parent: parent, deadline: deadline}
+
+	t := c.AfterFunc(d, func() {
+		ctx.cancel(context.DeadlineExceeded)
+		cancel()
+	})
+
+	return ctx, func() {
+		t.Stop()
+		ctx.cancel(context.Canceled)
+		cancel()
+	}
+}
+
+func (c *Clock) schedule(d time.Duration, t *timer) *timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// next returns the earliest timer due until the given time
+func (c *Clock) next(to time.Time) *timer {
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+
+	if len(c.timers) == 0 || c.timers[0].when.After(to) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+// remove removes the given timer and returns true if it was active
+func (c *Clock) remove(t *timer) bool {
+	for i, active := range c.timers {
+		if active == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type timer struct {
+	clock *Clock
+	when  time.Time
+	f     func()
+	ch    chan time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	active := t.clock.remove(t)
+	t.when = t.clock.now.Add(d)
+	t.clock.timers = append(t.clock.timers, t)
+	return active
+}
+
+func (t *timer) fire(now time.Time) {
+	if t.f != nil {
+		t.f()
+		return
+	}
+
+	// Drop the tick if the previous one is not received like time.Timer
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// timeoutCtx is a context with a deadline of the manual clock
+type timeoutCtx struct {
+	context.Context
+
+	parent   context.Context
+	mutex    sync.Mutex
+	deadline time.Time
+	err      error
+}
+
+func (c *timeoutCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timeoutCtx) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	// Report the error of the parent when the parent cancels the context, the
+	// inner context only knows the parent is done
+	if c.Context.Err() != nil {
+		return c.parent.Err()
+	}
+	return nil
+}
+
+// cancel sets the error of the context unless it is already done
+func (c *timeoutCtx) cancel(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err == nil && c.Context.Err() == nil {
+		c.err = err
+	}
+}
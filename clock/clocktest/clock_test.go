@@ -0,0 +1,163 @@
+package clocktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Advance", func(t *testing.T) {
+		c := NewClock(start)
+		c.Advance(time.Second)
+
+		assert.Equal(t, start.Add(time.Second), c.Now())
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		c := NewClock(start)
+		c.Advance(time.Second)
+
+		assert.Equal(t, time.Second, c.Since(start))
+	})
+
+	t.Run("Set never moves backward", func(t *testing.T) {
+		c := NewClock(start)
+		c.Set(start.Add(-time.Second))
+
+		assert.Equal(t, start, c.Now())
+	})
+
+	t.Run("AfterFunc fires the due timers in order", func(t *testing.T) {
+		c := NewClock(start)
+
+		var fired []time.Time
+		c.AfterFunc(2*time.Second, func() { fired = append(fired, c.Now()) })
+		c.AfterFunc(time.Second, func() { fired = append(fired, c.Now()) })
+		c.AfterFunc(time.Hour, func() { fired = append(fired, c.Now()) })
+		assert.Equal(t, 3, c.Timers())
+
+		c.Advance(3 * time.Second)
+		assert.Equal(t, []time.Time{start.Add(time.Second), start.Add(2 * time.Second)}, fired)
+		assert.Equal(t, start.Add(3*time.Second), c.Now())
+		assert.Equal(t, 1, c.Timers())
+	})
+
+	t.Run("AfterFunc fires the rescheduled timers", func(t *testing.T) {
+		c := NewClock(start)
+
+		var ticks int
+		var tick func()
+		tick = func() {
+			ticks++
+			c.AfterFunc(time.Second, tick)
+		}
+		c.AfterFunc(time.Second, tick)
+
+		c.Advance(5 * time.Second)
+		assert.Equal(t, 5, ticks)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		c := NewClock(start)
+
+		var fired bool
+		timer := c.AfterFunc(time.Second, func() { fired = true })
+		assert.True(t, timer.Stop())
+		assert.False(t, timer.Stop())
+
+		c.Advance(time.Second)
+		assert.False(t, fired)
+		assert.Nil(t, timer.C())
+	})
+
+	t.Run("NewTimer and Reset", func(t *testing.T) {
+		c := NewClock(start)
+
+		timer := c.NewTimer(time.Second)
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), <-timer.C())
+
+		assert.False(t, timer.Reset(time.Second))
+		assert.True(t, timer.Reset(2*time.Second))
+		c.Advance(time.Second)
+		assert.Len(t, timer.C(), 0)
+
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(3*time.Second), <-timer.C())
+	})
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		c := NewClock(start)
+
+		ctx, cancel := c.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, start.Add(time.Second), deadline)
+		assert.NoError(t, ctx.Err())
+
+		c.Advance(time.Second)
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	})
+
+	t.Run("WithTimeout on cancel", func(t *testing.T) {
+		c := NewClock(start)
+
+		ctx, cancel := c.WithTimeout(context.Background(), time.Second)
+		cancel()
+
+		<-ctx.Done()
+		assert.Equal(t, context.Canceled, ctx.Err())
+		assert.Equal(t, 0, c.Timers())
+	})
+
+	t.Run("WithTimeout on parent cancel", func(t *testing.T) {
+		c := NewClock(start)
+
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := c.WithTimeout(parent, time.Second)
+		defer cancel()
+
+		cancelParent()
+		<-ctx.Done()
+		c.Advance(time.Second)
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+
+	t.Run("WithTimeout with earlier parent deadline", func(t *testing.T) {
+		c := NewClock(start)
+
+		parent, cancelParent := c.WithTimeout(context.Background(), time.Second)
+		defer cancelParent()
+		ctx, cancel := c.WithTimeout(parent, 2*time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, start.Add(time.Second), deadline)
+
+		c.Advance(time.Second)
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	})
+
+	t.Run("WithTimeout with later parent deadline", func(t *testing.T) {
+		c := NewClock(start)
+
+		parent, cancelParent := c.WithTimeout(context.Background(), 2*time.Second)
+		defer cancelParent()
+		ctx, cancel := c.WithTimeout(parent, time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, start.Add(time.Second), deadline)
+	})
+}
@@ -4,9 +4,106 @@
 
 package shift
 
+import (
+	"context"
+	"time"
+
+	"github.com/mustafaturan/shift/histogram"
+)
+
 // Counter is an interface to increment, reset and fetch invocation stats
 type Counter interface {
 	Increment(metric string)
 	Stats(metrics ...string) map[string]uint32
 	Reset()
 }
+
+// StoppableCounter is a counter which is stopped on the circuit breaker
+// shutdown, the counters release their background resources like timers or
+// freeze their windows with Stop
+type StoppableCounter interface {
+	Counter
+	Stop()
+}
+
+// LatencyCounter is a counter which records the invocation durations into
+// histograms, the summary of the latencies is reported in the stats
+type LatencyCounter interface {
+	Counter
+	Record(latency time.Duration)
+	Latencies() histogram.Summary
+}
+
+// WindowCounter is a counter which covers a fixed duration with its buckets,
+// the window can't be shorter than the invocation timeout
+type WindowCounter interface {
+	Counter
+	Window() time.Duration
+}
+
+// InvocationCounter is a counter which counts the metrics of each invocation
+// at once instead of the separate increments, so the count windows keep the
+// metrics and the latency of an invocation in the same slot
+type InvocationCounter interface {
+	Counter
+
+	// CountInvocation counts the metrics of an invocation together and
+	// records its latency when timed
+	CountInvocation(metrics []string, latency time.Duration, timed bool)
+
+	// CountRejection counts the metrics of a rejected invocation together
+	// without taking the place of an executed invocation
+	CountRejection(metrics []string)
+}
+
+// tallyKey is the context key of the invocation tally
+type tallyKey struct{}
+
+// tally collects the metrics and the latency of an invocation to count them
+// together on the invocation counters
+type tally struct {
+	metrics  []string
+	latency  time.Duration
+	timed    bool
+	rejected bool
+}
+
+// withTally adds a tally to the context for the invocation counters
+func (s *Shift) withTally(ctx context.Context) context.Context {
+	if _, ok := s.counter.(InvocationCounter); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, tallyKey{}, &tally{})
+}
+
+// count counts the metric right away, or adds it to the tally of the
+// invocation for the invocation counters
+func (s *Shift) count(ctx context.Context, metric string) {
+	if t, ok := ctx.Value(tallyKey{}).(*tally); ok {
+		t.metrics = append(t.metrics, metric)
+		return
+	}
+	s.counter.Increment(metric)
+}
+
+// reject marks the tally of the invocation as rejected
+func (s *Shift) reject(ctx context.Context) {
+	if t, ok := ctx.Value(tallyKey{}).(*tally); ok {
+		t.rejected = true
+	}
+}
+
+// flush counts the tally of the invocation on the invocation counters
+func (s *Shift) flush(ctx context.Context) {
+	t, ok := ctx.Value(tallyKey{}).(*tally)
+	if !ok || (len(t.metrics) == 0 && !t.timed) {
+		return
+	}
+
+	c := s.counter.(InvocationCounter)
+	if t.rejected {
+		c.CountRejection(t.metrics)
+		return
+	}
+	c.CountInvocation(t.metrics, t.latency, t.timed)
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package counter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mustafaturan/shift/histogram"
+)
+
+// DefaultOutcomes are the metrics counted once per invocation by the shift
+// circuit breaker
+var DefaultOutcomes = []string{"success", "failure"}
+
+// CountWindowCounter is a counter which reports the stats of the last N
+// invocations regardless of the time they happened. The shift circuit breaker
+// counts the metrics and the latency of each invocation together in a slot of
+// a ring buffer and evicts the oldest one. The rejected invocations are counted
+// in a separate ring buffer of the same size, so they never evict the executed
+// invocations from the window. With the plain increments, every
+// increment of an outcome metric closes an invocation and the other metrics
+// like timeouts, rejections and slow calls are counted with the next outcome,
+// they are reported right away while pending.
+type CountWindowCounter struct {
+	mutex sync.RWMutex
+
+	outcomes []string
+	stats    bucket
+	latency  histogram.Histogram
+
+	// entries is the ring buffer of the metrics per invocation
+	entries []entry
+	next    int
+
+	// rejections is the ring buffer of the metrics per rejected invocation
+	rejections    []entry
+	nextRejection int
+
+	// pending holds the metrics counted since the last outcome
+	pending entry
+}
+
+// entry holds the metrics and latencies of an invocation
+type entry struct {
+	metrics   []string
+	latencies []time.Duration
+}
+
+// NewCountWindowCounter inits a counter with the given window size and
+// outcome metrics, the DefaultOutcomes are used without outcomes
+func NewCountWindowCounter(size int, outcomes ...string) (*CountWindowCounter, error) {
+	if size < 1 {
+		return nil, &InvalidOptionError{
+			Name: "count window counter size",
+			Type: "positive integer",
+		}
+	}
+
+	if len(outcomes) == 0 {
+		outcomes = DefaultOutcomes
+	}
+
+	return &CountWindowCounter{
+		outcomes:   append([]string(nil), outcomes...),
+		stats:      make(bucket),
+		entries:    make([]entry, size),
+		rejections: make([]entry, size),
+	}, nil
+}
+
+// Increment increments the given metric by 1
+func (c *CountWindowCounter) Increment(metric string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats[metric]++
+	c.pending.metrics = append(c.pending.metrics, metric)
+	if !c.isOutcome(metric) {
+		return
+	}
+
+	evicted := c.evict()
+
+	// Close the pending invocation and reuse the evicted slices to avoid
+	// allocations on the steady state
+	c.entries[c.next] = c.pending
+	c.pending = entry{
+		metrics:   evicted.metrics[:0],
+		latencies: evicted.latencies[:0],
+	}
+	c.next = (c.next + 1) % len(c.entries)
+}
+
+// CountInvocation counts the metrics of an invocation together in a slot of
+// the window and records its latency when timed, the oldest invocation is
+// evicted with all of its metrics and latencies
+func (c *CountWindowCounter) CountInvocation(metrics []string, latency time.Duration, timed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	evicted := c.evict()
+	e := entry{
+		metrics:   append(evicted.metrics[:0], metrics...),
+		latencies: evicted.latencies[:0],
+	}
+	for _, m := range metrics {
+		c.stats[m]++
+	}
+	if timed {
+		c.latency.Record(latency)
+		e.latencies = append(e.latencies, latency)
+	}
+
+	c.entries[c.next] = e
+	c.next = (c.next + 1) % len(c.entries)
+}
+
+// CountRejection counts the metrics of a rejected invocation together in a
+// slot of the rejection window, the oldest rejection is evicted with all of its
+// metrics
+func (c *CountWindowCounter) CountRejection(metrics []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	evicted := c.rejections[c.nextRejection]
+	for _, m := range evicted.metrics {
+		c.stats[m]--
+	}
+	for _, m := range metrics {
+		c.stats[m]++
+	}
+
+	c.rejections[c.nextRejection] = entry{metrics: append(evicted.metrics[:0], metrics...)}
+	c.nextRejection = (c.nextRejection + 1) % len(c.rejections)
+}
+
+// Stats returns the metric values for given metrics in the window
+func (c *CountWindowCounter) Stats(metrics ...string) map[string]uint32 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	stats := make(map[string]uint32)
+	for _, metric := range metrics {
+		stats[metric] = c.stats[metric]
+	}
+	return stats
+}
+
+// Record records the given invocation latency with the next outcome
+func (c *CountWindowCounter) Record(latency time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.latency.Record(latency)
+	c.pending.latencies = append(c.pending.latencies, latency)
+}
+
+// Latencies returns the summary of the invocation latencies in the window
+func (c *CountWindowCounter) Latencies() histogram.Summary {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.latency.Summary()
+}
+
+// Reset resets the stats and the window
+func (c *CountWindowCounter) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats = make(bucket)
+	c.latency.Reset()
+	for i := range c.entries {
+		c.entries[i] = entry{}
+	}
+	c.next = 0
+	for i := range c.rejections {
+		c.rejections[i] = entry{}
+	}
+	c.nextRejection = 0
+	c.pending = entry{}
+}
+
+// evict removes the metrics and the latencies of the oldest invocation from the
+// stats and returns its entry to reuse the slices
+func (c *CountWindowCounter) evict() entry {
+	evicted := c.entries[c.next]
+	for _, m := range evicted.metrics {
+		c.stats[m]--
+	}
+	for _, l := range evicted.latencies {
+		c.latency.Remove(l)
+	}
+	return evicted
+}
+
+func (c *CountWindowCounter) isOutcome(metric string) bool {
+	for _, m := range c.outcomes {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
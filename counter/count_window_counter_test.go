@@ -0,0 +1,222 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCountWindowCounter(t *testing.T) {
+	t.Run("with invalid size", func(t *testing.T) {
+		c, err := NewCountWindowCounter(0)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, c)
+	})
+
+	t.Run("with default outcomes", func(t *testing.T) {
+		c, err := NewCountWindowCounter(3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultOutcomes, c.outcomes)
+		assert.Len(t, c.entries, 3)
+	})
+
+	t.Run("with outcomes", func(t *testing.T) {
+		c, err := NewCountWindowCounter(3, "success", "failure", "not_found")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"success", "failure", "not_found"}, c.outcomes)
+	})
+}
+
+func TestCountWindowCounter_Increment(t *testing.T) {
+	t.Run("keeps the last invocations", func(t *testing.T) {
+		c, err := NewCountWindowCounter(3)
+		require.NoError(t, err)
+
+		c.Increment("failure")
+		c.Increment("failure")
+		c.Increment("success")
+		assert.Equal(t, map[string]uint32{"success": 1, "failure": 2}, c.Stats("success", "failure"))
+
+		// the first failure leaves the window
+		c.Increment("success")
+		assert.Equal(t, map[string]uint32{"success": 2, "failure": 1}, c.Stats("success", "failure"))
+
+		c.Increment("success")
+		c.Increment("success")
+		assert.Equal(t, map[string]uint32{"success": 3, "failure": 0}, c.Stats("success", "failure"))
+	})
+
+	t.Run("counts the other metrics with the next outcome", func(t *testing.T) {
+		c, err := NewCountWindowCounter(1)
+		require.NoError(t, err)
+
+		// the pending metrics are reported right away
+		c.Increment("timeout")
+		assert.Equal(t, uint32(1), c.Stats("timeout")["timeout"])
+
+		c.Increment("failure")
+		assert.Equal(t, map[string]uint32{"timeout": 1, "failure": 1}, c.Stats("timeout", "failure"))
+
+		// the timeout leaves the window with its failure
+		c.Increment("slow")
+		c.Increment("success")
+		assert.Equal(
+			t,
+			map[string]uint32{"timeout": 0, "failure": 0, "slow": 1, "success": 1},
+			c.Stats("timeout", "failure", "slow", "success"),
+		)
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		c, err := NewCountWindowCounter(100)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Increment("success")
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, uint32(100), c.Stats("success")["success"])
+	})
+}
+
+func TestCountWindowCounter_CountInvocation(t *testing.T) {
+	t.Run("evicts the invocations as a whole", func(t *testing.T) {
+		c, err := NewCountWindowCounter(2)
+		require.NoError(t, err)
+
+		c.CountInvocation([]string{"timeout", "failure", "fallback"}, 30*time.Millisecond, true)
+		c.CountInvocation([]string{"success"}, 10*time.Millisecond, true)
+		c.CountInvocation([]string{"reject", "failure"}, 0, false)
+
+		metrics := []string{"success", "failure", "timeout", "reject", "fallback"}
+		assert.Equal(t, map[string]uint32{"success": 1, "failure": 1, "timeout": 0, "reject": 1, "fallback": 0}, c.Stats(metrics...))
+
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(1), latencies.Count)
+		assert.Equal(t, 10*time.Millisecond, latencies.Mean)
+	})
+
+	t.Run("keeps the invocations together under concurrency", func(t *testing.T) {
+		size := 16
+		c, err := NewCountWindowCounter(size)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 64; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if (i+j)%2 == 0 {
+						c.CountInvocation([]string{"success"}, time.Millisecond, true)
+					} else {
+						c.CountInvocation([]string{"timeout", "failure", "fallback"}, time.Second, true)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		stats := c.Stats("success", "failure", "timeout", "fallback")
+		assert.Equal(t, uint32(size), stats["success"]+stats["failure"])
+		assert.Equal(t, stats["failure"], stats["timeout"])
+		assert.Equal(t, stats["failure"], stats["fallback"])
+
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(size), latencies.Count)
+		assert.Equal(t, time.Duration(stats["success"])*time.Millisecond+time.Duration(stats["failure"])*time.Second, latencies.Mean*time.Duration(size))
+	})
+}
+
+func TestCountWindowCounter_CountRejection(t *testing.T) {
+	c, err := NewCountWindowCounter(2)
+	require.NoError(t, err)
+
+	c.CountInvocation([]string{"success"}, 10*time.Millisecond, true)
+	c.CountInvocation([]string{"failure"}, 20*time.Millisecond, true)
+	for i := 0; i < 3; i++ {
+		c.CountRejection([]string{"reject", "failure", "fallback"})
+	}
+
+	metrics := []string{"success", "failure", "reject", "fallback"}
+	assert.Equal(t, map[string]uint32{"success": 1, "failure": 3, "reject": 2, "fallback": 2}, c.Stats(metrics...))
+	assert.Equal(t, uint32(2), c.Latencies().Count)
+
+	c.Reset()
+	assert.Equal(t, map[string]uint32{"success": 0, "failure": 0, "reject": 0, "fallback": 0}, c.Stats(metrics...))
+}
+
+func TestCountWindowCounter_Stats(t *testing.T) {
+	c, err := NewCountWindowCounter(10)
+	require.NoError(t, err)
+
+	c.Increment("success")
+	c.Increment("reject")
+	c.Increment("failure")
+
+	assert.Equal(
+		t,
+		map[string]uint32{"success": 1, "failure": 1, "reject": 1, "timeout": 0},
+		c.Stats("success", "failure", "reject", "timeout"),
+	)
+}
+
+func TestCountWindowCounter_Reset(t *testing.T) {
+	c, err := NewCountWindowCounter(2)
+	require.NoError(t, err)
+
+	c.Increment("success")
+	c.Increment("timeout")
+	c.Record(time.Millisecond)
+	c.Reset()
+
+	assert.Equal(t, map[string]uint32{"success": 0, "timeout": 0}, c.Stats("success", "timeout"))
+	assert.Equal(t, 0, c.next)
+	assert.Empty(t, c.pending)
+	assert.Equal(t, uint32(0), c.Latencies().Count)
+
+	c.Increment("failure")
+	c.Increment("failure")
+	c.Increment("failure")
+	assert.Equal(t, uint32(2), c.Stats("failure")["failure"])
+}
+
+func TestCountWindowCounter_Latencies(t *testing.T) {
+	c, err := NewCountWindowCounter(2)
+	require.NoError(t, err)
+
+	for _, latency := range []time.Duration{10, 20, 30} {
+		c.Record(latency * time.Millisecond)
+		c.Increment("success")
+	}
+
+	// the latency of the evicted invocation is removed
+	latencies := c.Latencies()
+	assert.Equal(t, uint32(2), latencies.Count)
+	assert.Equal(t, 25*time.Millisecond, latencies.Mean)
+
+	t.Run("the pending latencies are reported right away", func(t *testing.T) {
+		c.Record(40 * time.Millisecond)
+		assert.Equal(t, uint32(3), c.Latencies().Count)
+
+		c.Increment("failure")
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(2), latencies.Count)
+		assert.Equal(t, 35*time.Millisecond, latencies.Mean)
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package counter
+
+import "github.com/mustafaturan/shift/clock"
+
+// Option is a type for counter options
+type Option func(*options) error
+
+type options struct {
+	clock clock.Clock
+}
+
+// newOptions builds the counter options with defaults
+func newOptions(opts []Option) (*options, error) {
+	o := &options{clock: clock.NewSystemClock()}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithClock builds option to set the clock of the counter
+func WithClock(c clock.Clock) Option {
+	return func(o *options) error {
+		if c == nil {
+			return &InvalidOptionError{
+				Name: "counter clock",
+				Type: "non-nil clock",
+			}
+		}
+		o.clock = c
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package counter
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/histogram"
+)
+
+// DefaultMetrics are the metrics counted by the shift circuit breaker, the
+// custom outcomes of the error classifiers need to be appended with their
+// "custom:" prefix to count them
+var DefaultMetrics = []string{
+	"success",
+	"failure",
+	"timeout",
+	"reject",
+	"fallback",
+	"slow",
+}
+
+// SlidingWindowCounter is a lock-free bucket counter with fixed metric slots.
+// Each slot of a bucket packs the epoch of the bucket and the count into a
+// single atomic word, so the stale buckets are rotated lazily on access
+// without timers and the idle counters cost nothing. The latencies are recorded
+// into a histogram per bucket which is guarded by its own lock, so only the
+// latency records of the same bucket contend.
+type SlidingWindowCounter struct {
+	metrics  []string
+	capacity int64
+	duration int64
+
+	clock clock.Clock
+	start time.Time
+
+	// cells holds the packed epoch and count per bucket and metric slot
+	cells []uint64
+
+	// latencies holds the histogram per bucket
+	latencies []latencyBucket
+}
+
+// latencyBucket is the latency histogram of a bucket with its epoch, the
+// histogram of a stale epoch is reset lazily on the next record
+type latencyBucket struct {
+	mutex     sync.Mutex
+	epoch     uint32
+	histogram histogram.Histogram
+}
+
+// NewSlidingWindowCounter inits a counter with the given number of buckets,
+// bucket duration and metrics, the other metrics are ignored
+func NewSlidingWindowCounter(capacity int, duration time.Duration, metrics []string, opts ...Option) (*SlidingWindowCounter, error) {
+	if err := validateWindow("sliding window counter", capacity, duration); err != nil {
+		return nil, err
+	}
+
+	if len(metrics) == 0 {
+		return nil, &InvalidOptionError{
+			Name: "sliding window counter metrics",
+			Type: "non-empty list",
+		}
+	}
+
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SlidingWindowCounter{
+		metrics:   append([]string(nil), metrics...),
+		capacity:  int64(capacity),
+		duration:  int64(duration),
+		clock:     o.clock,
+		start:     o.clock.Now(),
+		cells:     make([]uint64, capacity*len(metrics)),
+		latencies: make([]latencyBucket, capacity),
+	}, nil
+}
+
+// Increment increments the given metric by 1
+func (c *SlidingWindowCounter) Increment(metric string) {
+	slot := c.slot(metric)
+	if slot < 0 {
+		return
+	}
+
+	epoch := c.epoch()
+	cell := &c.cells[c.index(epoch, slot)]
+	for {
+		old := atomic.LoadUint64(cell)
+
+		// Rotate the stale bucket on the first increment of the epoch
+		next := pack(epoch, 1)
+		if e, count := unpack(old); e == epoch {
+			next = pack(epoch, count+1)
+		}
+
+		if atomic.CompareAndSwapUint64(cell, old, next) {
+			return
+		}
+	}
+}
+
+// Stats returns the metric values for given metrics in the window
+func (c *SlidingWindowCounter) Stats(metrics ...string) map[string]uint32 {
+	epoch := c.epoch()
+
+	stats := make(map[string]uint32)
+	for _, metric := range metrics {
+		stats[metric] = 0
+
+		slot := c.slot(metric)
+		if slot < 0 {
+			continue
+		}
+
+		for i := int64(0); i < c.capacity; i++ {
+			e, count := unpack(atomic.LoadUint64(&c.cells[c.index(epoch-uint32(i), slot)]))
+			if e == epoch-uint32(i) {
+				stats[metric] += count
+			}
+		}
+	}
+	return stats
+}
+
+// Record records the given invocation latency into the current bucket
+func (c *SlidingWindowCounter) Record(latency time.Duration) {
+	epoch := c.epoch()
+	b := &c.latencies[int64(epoch)%c.capacity]
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// Rotate the stale bucket on the first record of the epoch
+	if b.epoch != epoch {
+		b.histogram.Reset()
+		b.epoch = epoch
+	}
+	b.histogram.Record(latency)
+}
+
+// Latencies returns the summary of the invocation latencies in the window
+func (c *SlidingWindowCounter) Latencies() histogram.Summary {
+	epoch := c.epoch()
+
+	var window histogram.Histogram
+	for i := int64(0); i < c.capacity; i++ {
+		e := epoch - uint32(i)
+		b := &c.latencies[int64(e)%c.capacity]
+
+		b.mutex.Lock()
+		if b.epoch == e {
+			window.Merge(&b.histogram)
+		}
+		b.mutex.Unlock()
+	}
+	return window.Summary()
+}
+
+// Window returns the duration covered by the buckets
+func (c *SlidingWindowCounter) Window() time.Duration {
+	return time.Duration(c.capacity * c.duration)
+}
+
+// Reset resets the stats and buckets
+func (c *SlidingWindowCounter) Reset() {
+	// The zero cell has no count for any epoch
+	for i := range c.cells {
+		atomic.StoreUint64(&c.cells[i], 0)
+	}
+
+	for i := range c.latencies {
+		b := &c.latencies[i]
+		b.mutex.Lock()
+		b.histogram.Reset()
+		b.mutex.Unlock()
+	}
+}
+
+// slot returns the index of the metric in the fixed metric slots, a linear
+// scan over a few metrics is cheaper than a map lookup
+func (c *SlidingWindowCounter) slot(metric string) int {
+	for i, m := range c.metrics {
+		if m == metric {
+			return i
+		}
+	}
+	return -1
+}
+
+// epoch returns the number of bucket durations since the counter start, it
+// wraps around after 2^32 durations
+func (c *SlidingWindowCounter) epoch() uint32 {
+	return uint32(int64(c.clock.Since(c.start)) / c.duration)
+}
+
+// index returns the cell index of the given epoch and metric slot
+func (c *SlidingWindowCounter) index(epoch uint32, slot int) int {
+	bucket := int64(epoch) % c.capacity
+	return int(bucket)*len(c.metrics) + slot
+}
+
+func pack(epoch, count uint32) uint64 {
+	return uint64(epoch)<<32 | uint64(count)
+}
+
+func unpack(cell uint64) (uint32, uint32) {
+	return uint32(cell >> 32), uint32(cell)
+}
@@ -0,0 +1,235 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlidingWindowCounter(t *testing.T) {
+	metrics := []string{"success", "failure"}
+
+	tests := []struct {
+		desc     string
+		capacity int
+		duration time.Duration
+		metrics  []string
+		opts     []Option
+	}{
+		{"with invalid capacity", 0, time.Second, metrics, nil},
+		{"with invalid duration", 1, time.Microsecond, metrics, nil},
+		{"without metrics", 1, time.Second, nil, nil},
+		{"with invalid option", 1, time.Second, metrics, []Option{WithClock(nil)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			c, err := NewSlidingWindowCounter(test.capacity, test.duration, test.metrics, test.opts...)
+
+			assert.Error(t, err)
+			assert.IsType(t, &InvalidOptionError{}, err)
+			assert.Nil(t, c)
+		})
+	}
+
+	t.Run("with valid options", func(t *testing.T) {
+		now := time.Now()
+		clk := clocktest.NewClock(now)
+		c, err := NewSlidingWindowCounter(3, time.Second, metrics, WithClock(clk))
+
+		assert.NoError(t, err)
+		assert.Equal(t, metrics, c.metrics)
+		assert.Equal(t, int64(3), c.capacity)
+		assert.Equal(t, int64(time.Second), c.duration)
+		assert.Equal(t, now, c.start)
+		assert.Len(t, c.cells, 6)
+
+		// no timers are scheduled
+		assert.Equal(t, 0, clk.Timers())
+	})
+}
+
+func TestSlidingWindowCounter_Increment(t *testing.T) {
+	metric, unknown := "success", "unknown"
+	capacity, duration := 2, time.Second
+
+	t.Run("increments on stats", func(t *testing.T) {
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clocktest.NewClock(time.Now())))
+		c.Increment(metric)
+		c.Increment(metric)
+		c.Increment(unknown)
+
+		metrics := c.Stats(metric, unknown)
+		assert.Equal(t, map[string]uint32{metric: 2, unknown: 0}, metrics)
+	})
+
+	t.Run("drops the stale buckets lazily", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clk))
+		c.Increment(metric)
+
+		clk.Advance(duration)
+		c.Increment(metric)
+		assert.Equal(t, uint32(2), c.Stats(metric)[metric])
+
+		// the first bucket leaves the window
+		clk.Advance(duration)
+		assert.Equal(t, uint32(1), c.Stats(metric)[metric])
+
+		// the rotated bucket starts over
+		c.Increment(metric)
+		assert.Equal(t, uint32(2), c.Stats(metric)[metric])
+
+		// the idle counter drops all buckets
+		clk.Advance(time.Duration(capacity) * duration)
+		assert.Equal(t, uint32(0), c.Stats(metric)[metric])
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clocktest.NewClock(time.Now())))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Increment(metric)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, uint32(1000), c.Stats(metric)[metric])
+	})
+}
+
+func TestSlidingWindowCounter_Stats(t *testing.T) {
+	c, err := NewSlidingWindowCounter(3, time.Second, DefaultMetrics, WithClock(clocktest.NewClock(time.Now())))
+	require.NoError(t, err)
+
+	c.Increment("success")
+	c.Increment("success")
+	c.Increment("failure")
+
+	assert.Equal(
+		t,
+		map[string]uint32{"success": 2, "failure": 1, "timeout": 0},
+		c.Stats("success", "failure", "timeout"),
+	)
+}
+
+func TestSlidingWindowCounter_Record(t *testing.T) {
+	capacity, duration := 2, time.Second
+
+	t.Run("records on latencies", func(t *testing.T) {
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clocktest.NewClock(time.Now())))
+		c.Record(10 * time.Millisecond)
+		c.Record(20 * time.Millisecond)
+
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(2), latencies.Count)
+		assert.Equal(t, 15*time.Millisecond, latencies.Mean)
+	})
+
+	t.Run("drops the stale buckets lazily", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clk))
+		c.Record(10 * time.Millisecond)
+
+		clk.Advance(duration)
+		c.Record(30 * time.Millisecond)
+		assert.Equal(t, uint32(2), c.Latencies().Count)
+
+		// the first bucket leaves the window
+		clk.Advance(duration)
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(1), latencies.Count)
+		assert.Equal(t, 30*time.Millisecond, latencies.Mean)
+
+		// the rotated bucket starts over
+		c.Record(50 * time.Millisecond)
+		latencies = c.Latencies()
+		assert.Equal(t, uint32(2), latencies.Count)
+		assert.Equal(t, 40*time.Millisecond, latencies.Mean)
+
+		// the idle counter drops all buckets
+		clk.Advance(time.Duration(capacity) * duration)
+		assert.Equal(t, uint32(0), c.Latencies().Count)
+	})
+
+	t.Run("concurrent records", func(t *testing.T) {
+		c, _ := NewSlidingWindowCounter(capacity, duration, DefaultMetrics, WithClock(clocktest.NewClock(time.Now())))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Record(time.Millisecond)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, uint32(1000), c.Latencies().Count)
+	})
+}
+
+func TestSlidingWindowCounter_Window(t *testing.T) {
+	c, err := NewSlidingWindowCounter(10, 100*time.Millisecond, DefaultMetrics)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, c.Window())
+}
+
+func TestSlidingWindowCounter_Reset(t *testing.T) {
+	clk := clocktest.NewClock(time.Now())
+	c, err := NewSlidingWindowCounter(3, time.Second, DefaultMetrics, WithClock(clk))
+	require.NoError(t, err)
+
+	c.Increment("success")
+	c.Record(time.Millisecond)
+	clk.Advance(time.Second)
+	c.Increment("success")
+	c.Record(time.Millisecond)
+	c.Reset()
+
+	assert.Equal(t, uint32(0), c.Stats("success")["success"])
+	assert.Equal(t, uint32(0), c.Latencies().Count)
+
+	c.Increment("success")
+	assert.Equal(t, uint32(1), c.Stats("success")["success"])
+}
+
+func BenchmarkCounter_Increment(b *testing.B) {
+	bucketed, _ := NewTimeBucketCounter(10, time.Second)
+	sliding, _ := NewSlidingWindowCounter(10, time.Second, DefaultMetrics)
+	counted, _ := NewCountWindowCounter(100)
+
+	counters := []struct {
+		name    string
+		counter interface{ Increment(string) }
+	}{
+		{"TimeBucketCounter", bucketed},
+		{"SlidingWindowCounter", sliding},
+		{"CountWindowCounter", counted},
+	}
+
+	for _, c := range counters {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.counter.Increment("success")
+				}
+			})
+		})
+	}
+	bucketed.Stop()
+}
@@ -5,43 +5,57 @@
 package counter
 
 import (
+	"math"
 	"sync"
 	"time"
+
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/histogram"
 )
 
+// MinBucketDuration is the finest bucket duration of the counters
+const MinBucketDuration = time.Millisecond
+
 type bucket map[string]uint32
 
 // TimeBucketCounter is a capped bucket counter with a feature of auto drops of
-// the stale buckets on given duration
+// the stale buckets on given duration. The stale buckets are dropped lazily by
+// the elapsed time on access, so no timers are scheduled per bucket.
 type TimeBucketCounter struct {
-	mutex sync.RWMutex
+	mutex sync.Mutex
 
 	stats   bucket
 	buckets []bucket
 
+	// latency is the histogram of the window, latencies are the histograms
+	// per bucket
+	latency   histogram.Histogram
+	latencies []histogram.Histogram
+
 	duration time.Duration
-	timer    *time.Timer
+	clock    clock.Clock
+
+	// rotatedAt is the start time of the last bucket
+	rotatedAt time.Time
+	stopped   bool
 }
 
 // NewTimeBucketCounter inits and returns stats with given options
-func NewTimeBucketCounter(capacity int, duration time.Duration) (*TimeBucketCounter, error) {
-	if capacity < 1 {
-		return nil, &InvalidOptionError{
-			Name: "time bucket counter capacity",
-			Type: "positive integer",
-		}
+func NewTimeBucketCounter(capacity int, duration time.Duration, opts ...Option) (*TimeBucketCounter, error) {
+	if err := validateWindow("time bucket counter", capacity, duration); err != nil {
+		return nil, err
 	}
 
-	if duration < time.Second {
-		return nil, &InvalidOptionError{
-			Name: "time bucket counter duration",
-			Type: "positive duration(greater than or equal to a second)",
-		}
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	counter := &TimeBucketCounter{
-		buckets:  make([]bucket, capacity),
-		duration: duration,
+		buckets:   make([]bucket, capacity),
+		latencies: make([]histogram.Histogram, capacity),
+		duration:  duration,
+		clock:     o.clock,
 	}
 	defer counter.Reset()
 
@@ -56,7 +70,20 @@ func (c *TimeBucketCounter) Reset() {
 	// Reset attributes
 	c.resetStats()
 	c.resetBuckets()
-	c.resetTimer()
+	c.rotatedAt = c.clock.Now()
+}
+
+// Stop freezes the window by stopping the drops of the stale buckets, so the
+// stats of a shut down circuit breaker keep reporting its last window instead
+// of decaying to zero. The counter owns no timers to release since the buckets
+// rotate lazily on access. The increments after the stop are counted in the
+// last bucket, Reset still clears the stats and a stopped counter can't be
+// restarted.
+func (c *TimeBucketCounter) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stopped = true
 }
 
 // Increment increments the given metric by 1
@@ -64,15 +91,17 @@ func (c *TimeBucketCounter) Increment(metric string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	c.rotate()
 	c.stats[metric]++
 	c.buckets[len(c.buckets)-1][metric]++
 }
 
 // Stats returns the metric values for given metrics
 func (c *TimeBucketCounter) Stats(metrics ...string) map[string]uint32 {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
+	c.rotate()
 	stats := make(map[string]uint32)
 	for _, metric := range metrics {
 		stats[metric] = c.stats[metric]
@@ -80,42 +109,114 @@ func (c *TimeBucketCounter) Stats(metrics ...string) map[string]uint32 {
 	return stats
 }
 
-func (c *TimeBucketCounter) drop() {
+// Record records the given invocation latency
+func (c *TimeBucketCounter) Record(latency time.Duration) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Assign a new task to drop in the future
-	c.timer = time.AfterFunc(c.duration, c.drop)
+	c.rotate()
+	c.latency.Record(latency)
+	c.latencies[len(c.latencies)-1].Record(latency)
+}
 
+// Latencies returns the summary of the invocation latencies in the window
+func (c *TimeBucketCounter) Latencies() histogram.Summary {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.rotate()
+	return c.latency.Summary()
+}
+
+// Window returns the duration covered by the buckets
+func (c *TimeBucketCounter) Window() time.Duration {
+	return time.Duration(len(c.buckets)) * c.duration
+}
+
+// rotate drops the buckets which are elapsed since the last rotation
+func (c *TimeBucketCounter) rotate() {
+	if c.stopped {
+		return
+	}
+
+	elapsed := int64(c.clock.Since(c.rotatedAt) / c.duration)
+	if elapsed <= 0 {
+		return
+	}
+
+	// Keep the bucket boundaries aligned regardless of the access times
+	c.rotatedAt = c.rotatedAt.Add(time.Duration(elapsed) * c.duration)
+
+	if elapsed >= int64(len(c.buckets)) {
+		c.resetStats()
+		c.resetBuckets()
+		return
+	}
+
+	for i := int64(0); i < elapsed; i++ {
+		c.drop()
+	}
+}
+
+func (c *TimeBucketCounter) drop() {
 	// Drop the metrics for the fist bucket
 	for metric := range c.stats {
 		c.stats[metric] -= c.buckets[0][metric]
 	}
 
+	// Drop the latencies for the first bucket
+	stale := c.latencies[0]
+	c.latency.Subtract(&stale)
+
 	// Drop the stale bucket(shift left until the last bucket)
 	for i := 0; i < len(c.buckets)-1; i++ {
 		c.buckets[i] = c.buckets[i+1]
+		c.latencies[i] = c.latencies[i+1]
 	}
 
-	// Reset the last bucket
+	// Reset the last bucket, the stale histogram is reused to avoid
+	// allocations on the steady state
 	c.buckets[len(c.buckets)-1] = make(bucket)
+	stale.Reset()
+	c.latencies[len(c.latencies)-1] = stale
 }
 
 func (c *TimeBucketCounter) resetStats() {
 	c.stats = make(bucket)
+	c.latency.Reset()
 }
 
 func (c *TimeBucketCounter) resetBuckets() {
 	for i := 0; i < len(c.buckets); i++ {
 		c.buckets[i] = make(bucket)
+		c.latencies[i].Reset()
 	}
 }
 
-func (c *TimeBucketCounter) resetTimer() {
-	if c.timer != nil {
-		c.timer.Stop()
+// validateWindow validates the bucket capacity and duration of the time based
+// counters
+func validateWindow(name string, capacity int, duration time.Duration) error {
+	if capacity < 1 {
+		return &InvalidOptionError{
+			Name: name + " capacity",
+			Type: "positive integer",
+		}
+	}
+
+	if duration < MinBucketDuration {
+		return &InvalidOptionError{
+			Name: name + " duration",
+			Type: "positive duration(greater than or equal to a millisecond)",
+		}
+	}
+
+	// The window of the buckets can't be longer than the max duration
+	if int64(capacity) > math.MaxInt64/int64(duration) {
+		return &InvalidOptionError{
+			Name: name + " capacity and duration",
+			Type: "pair with a window(capacity x duration) fitting into a duration",
+		}
 	}
 
-	// Assign a new task to drop the stale bucket in the future
-	c.timer = time.AfterFunc(c.duration, c.drop)
+	return nil
 }
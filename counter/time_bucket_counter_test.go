@@ -1,9 +1,11 @@
 package counter
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/mustafaturan/shift/clock/clocktest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,18 +18,44 @@ func TestNewTimeBucketCounter(t *testing.T) {
 	})
 
 	t.Run("with invalid duration", func(t *testing.T) {
-		c, err := NewTimeBucketCounter(1, time.Millisecond)
+		c, err := NewTimeBucketCounter(1, time.Microsecond)
 		assert.Error(t, err)
 		assert.IsType(t, &InvalidOptionError{}, err)
 		assert.Nil(t, c)
 	})
 
+	t.Run("with overflowing window", func(t *testing.T) {
+		c, err := NewTimeBucketCounter(math.MaxInt32, time.Duration(math.MaxInt64/2))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, c)
+	})
+
+	t.Run("with invalid clock", func(t *testing.T) {
+		c, err := NewTimeBucketCounter(1, time.Second, WithClock(nil))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, c)
+	})
+
+	t.Run("with clock", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		c, err := NewTimeBucketCounter(1, time.Second, WithClock(clk))
+
+		assert.NoError(t, err)
+		assert.Equal(t, clk, c.clock)
+		assert.Equal(t, clk.Now(), c.rotatedAt)
+
+		// the stale buckets are dropped without timers
+		assert.Equal(t, 0, clk.Timers())
+	})
+
 	t.Run("with valid options", func(t *testing.T) {
 		capacity, duration := 3, 5*time.Second
 		c, err := NewTimeBucketCounter(capacity, duration)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, c.timer)
+		assert.False(t, c.rotatedAt.IsZero())
 		assert.Equal(t, capacity, len(c.buckets))
 		assert.Equal(t, duration, c.duration)
 	})
@@ -39,7 +67,7 @@ func TestIncrement(t *testing.T) {
 
 	t.Run("increments on stats and buckets", func(t *testing.T) {
 		count := uint32(1)
-		c, _ := NewTimeBucketCounter(capacity, duration)
+		c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clocktest.NewClock(time.Now())))
 		for i := 0; i < int(count); i++ {
 			c.Increment(metric)
 		}
@@ -51,7 +79,8 @@ func TestIncrement(t *testing.T) {
 
 	t.Run("decrements with scheduled auto drop", func(t *testing.T) {
 		count := uint32(2)
-		c, _ := NewTimeBucketCounter(capacity, duration)
+		clk := clocktest.NewClock(time.Now())
+		c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
 		for i := 0; i < int(count); i++ {
 			c.Increment(metric)
 		}
@@ -60,8 +89,13 @@ func TestIncrement(t *testing.T) {
 		metrics := c.Stats(metric)
 		assert.Equal(t, count, metrics[metric])
 
-		// The incremented metrics will be dropped at (capacity+1)*duration later
-		time.Sleep(time.Duration(capacity+1) * duration)
+		// The incremented metrics are kept until the last drop
+		clk.Advance(time.Duration(capacity-1) * duration)
+		metrics = c.Stats(metric)
+		assert.Equal(t, count, metrics[metric])
+
+		// The incremented metrics will be dropped at capacity*duration later
+		clk.Advance(duration)
 
 		metrics = c.Stats(metric)
 		assert.Equal(t, uint32(0), metrics[metric])
@@ -72,7 +106,7 @@ func TestStats(t *testing.T) {
 	metric1, metric2 := "test_1", "test_2"
 
 	capacity, duration := 3, time.Second
-	c, _ := NewTimeBucketCounter(capacity, duration)
+	c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clocktest.NewClock(time.Now())))
 	c.Increment(metric1)
 	c.Increment(metric1)
 	c.Increment(metric2)
@@ -86,13 +120,127 @@ func TestReset(t *testing.T) {
 	count, metric := uint32(0), "test"
 
 	capacity, duration := 3, time.Second
-	c, _ := NewTimeBucketCounter(capacity, duration)
+	clk := clocktest.NewClock(time.Now())
+	c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
 	c.Increment(metric)
 
-	timer := c.timer
+	clk.Advance(duration / 2)
 	c.Reset()
 
 	assert.Equal(t, count, c.stats[metric])
 	assert.Equal(t, count, c.buckets[2][metric])
-	assert.NotEqual(t, timer, c.timer)
+	assert.Equal(t, clk.Now(), c.rotatedAt)
+}
+
+func TestWindow(t *testing.T) {
+	c, err := NewTimeBucketCounter(10, 100*time.Millisecond)
+	assert.NoError(t, err)
+	defer c.Stop()
+
+	assert.Equal(t, time.Second, c.Window())
+}
+
+func TestStop(t *testing.T) {
+	metric := "test"
+
+	capacity, duration := 1, time.Second
+	clk := clocktest.NewClock(time.Now())
+	c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
+	c.Increment(metric)
+	c.Stop()
+
+	assert.True(t, c.stopped)
+
+	t.Run("keeps the stats", func(t *testing.T) {
+		clk.Advance(time.Duration(capacity+1) * duration)
+
+		metrics := c.Stats(metric)
+		assert.Equal(t, uint32(1), metrics[metric])
+	})
+}
+
+func TestRotate(t *testing.T) {
+	metric := "test"
+
+	t.Run("with sub-second buckets", func(t *testing.T) {
+		capacity, duration := 10, 100*time.Millisecond
+		clk := clocktest.NewClock(time.Now())
+		c, err := NewTimeBucketCounter(capacity, duration, WithClock(clk))
+		assert.NoError(t, err)
+
+		for i := 0; i < capacity; i++ {
+			c.Increment(metric)
+			clk.Advance(duration)
+		}
+		assert.Equal(t, uint32(capacity-1), c.Stats(metric)[metric])
+
+		clk.Advance(5 * duration)
+		assert.Equal(t, uint32(4), c.Stats(metric)[metric])
+	})
+
+	t.Run("keeps the bucket boundaries on late access", func(t *testing.T) {
+		capacity, duration := 3, time.Second
+		start := time.Now()
+		clk := clocktest.NewClock(start)
+		c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
+
+		clk.Advance(1500 * time.Millisecond)
+		c.Increment(metric)
+		assert.Equal(t, start.Add(duration), c.rotatedAt)
+
+		// the bucket of the increment expires at 4s, not at 4.5s
+		clk.Advance(2500 * time.Millisecond)
+		assert.Equal(t, uint32(0), c.Stats(metric)[metric])
+	})
+
+	t.Run("drops all buckets after an idle window", func(t *testing.T) {
+		capacity, duration := 3, 10*time.Millisecond
+		clk := clocktest.NewClock(time.Now())
+		c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
+		c.Increment(metric)
+
+		clk.Advance(time.Hour)
+		assert.Equal(t, uint32(0), c.Stats(metric)[metric])
+
+		c.Increment(metric)
+		assert.Equal(t, uint32(1), c.Stats(metric)[metric])
+	})
+}
+
+func TestLatencies(t *testing.T) {
+	capacity, duration := 3, 100*time.Millisecond
+	clk := clocktest.NewClock(time.Now())
+	c, _ := NewTimeBucketCounter(capacity, duration, WithClock(clk))
+
+	c.Record(10 * time.Millisecond)
+	clk.Advance(duration)
+	c.Record(20 * time.Millisecond)
+	c.Record(30 * time.Millisecond)
+
+	latencies := c.Latencies()
+	assert.Equal(t, uint32(3), latencies.Count)
+	assert.Equal(t, 20*time.Millisecond, latencies.Mean)
+	assert.Equal(t, 20*time.Millisecond, latencies.P50.Truncate(time.Millisecond))
+	assert.Equal(t, 30*time.Millisecond, latencies.Max.Truncate(time.Millisecond))
+
+	t.Run("drops the latencies of the stale buckets", func(t *testing.T) {
+		clk.Advance(time.Duration(capacity-1) * duration)
+
+		latencies := c.Latencies()
+		assert.Equal(t, uint32(2), latencies.Count)
+		assert.Equal(t, 25*time.Millisecond, latencies.Mean)
+
+		// the histogram of the stale bucket is reused for the new bucket
+		c.Record(40 * time.Millisecond)
+		assert.Equal(t, uint32(1), c.latencies[capacity-1].Count())
+	})
+
+	t.Run("resets the latencies", func(t *testing.T) {
+		c.Reset()
+
+		assert.Equal(t, uint32(0), c.Latencies().Count)
+		for _, h := range c.latencies {
+			assert.Equal(t, uint32(0), h.Count())
+		}
+	})
 }
@@ -10,3 +10,16 @@ func TestCounter(t *testing.T) {
 	// Ensure TimeBucketCounter implements Counter on build
 	var _ Counter = (*counter.TimeBucketCounter)(nil)
 }
+
+func TestWindowCounter(t *testing.T) {
+	// Ensure the time based counters implement WindowCounter on build
+	var _ WindowCounter = (*counter.TimeBucketCounter)(nil)
+	var _ WindowCounter = (*counter.SlidingWindowCounter)(nil)
+}
+
+func TestLatencyCounter(t *testing.T) {
+	// Ensure the latency counters implement LatencyCounter on build
+	var _ LatencyCounter = (*counter.TimeBucketCounter)(nil)
+	var _ LatencyCounter = (*counter.CountWindowCounter)(nil)
+	var _ LatencyCounter = (*counter.SlidingWindowCounter)(nil)
+}
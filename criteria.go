@@ -0,0 +1,41 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+// Combination is a strategy to combine the ratio and the consecutive criteria
+// of the openers and the closer
+type Combination int8
+
+const (
+	// CombineAny trips when any of the criteria matches
+	CombineAny Combination = iota
+
+	// CombineAll trips when all of the criteria match
+	CombineAll
+)
+
+func (c Combination) isValid() bool {
+	return c == CombineAny || c == CombineAll
+}
+
+// criteria holds the ratio and the consecutive policies to trip a state
+type criteria struct {
+	ratio       TripPolicy
+	consecutive TripPolicy
+	combination Combination
+}
+
+// policy combines the ratio and the consecutive policies with the combination
+// strategy
+func (c *criteria) policy() TripPolicy {
+	if c.consecutive == nil {
+		return c.ratio
+	}
+
+	if c.combination == CombineAll {
+		return AllPolicy(c.ratio, c.consecutive)
+	}
+	return AnyPolicy(c.ratio, c.consecutive)
+}
@@ -0,0 +1,51 @@
+package shift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCriteria_Policy(t *testing.T) {
+	var ratio TripPolicyFunc = func(state State, stats Stats, _ Outcome) State {
+		if stats.FailureCount > 5 {
+			return StateOpen
+		}
+		return state
+	}
+	var consecutive TripPolicyFunc = func(state State, stats Stats, _ Outcome) State {
+		if stats.ConsecutiveFailures > 2 {
+			return StateOpen
+		}
+		return state
+	}
+
+	tests := []struct {
+		desc     string
+		c        *criteria
+		stats    Stats
+		expected State
+	}{
+		{"only ratio unmatched", &criteria{ratio: ratio}, Stats{ConsecutiveFailures: 3}, StateClose},
+		{"only ratio matched", &criteria{ratio: ratio}, Stats{FailureCount: 6}, StateOpen},
+		{"any with ratio matched", &criteria{ratio: ratio, consecutive: consecutive}, Stats{FailureCount: 6}, StateOpen},
+		{"any with consecutive matched", &criteria{ratio: ratio, consecutive: consecutive}, Stats{ConsecutiveFailures: 3}, StateOpen},
+		{"any unmatched", &criteria{ratio: ratio, consecutive: consecutive}, Stats{}, StateClose},
+		{"all with ratio matched", &criteria{ratio: ratio, consecutive: consecutive, combination: CombineAll}, Stats{FailureCount: 6}, StateClose},
+		{"all with consecutive matched", &criteria{ratio: ratio, consecutive: consecutive, combination: CombineAll}, Stats{ConsecutiveFailures: 3}, StateClose},
+		{"all matched", &criteria{ratio: ratio, consecutive: consecutive, combination: CombineAll}, Stats{FailureCount: 6, ConsecutiveFailures: 3}, StateOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			to := test.c.policy().Evaluate(StateClose, test.stats, OutcomeFailure)
+			assert.Equal(t, test.expected, to)
+		})
+	}
+}
+
+func TestCombination_IsValid(t *testing.T) {
+	assert.True(t, CombineAny.isValid())
+	assert.True(t, CombineAll.isValid())
+	assert.False(t, Combination(42).isValid())
+}
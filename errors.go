@@ -57,6 +57,36 @@ func (e *IsOnOpenStateError) Error() string {
 	return "is on open state"
 }
 
+// HalfOpenProbesExceededError is an error type for rejecting the invocations
+// above the max number of in-flight probes on half-open state
+type HalfOpenProbesExceededError struct {
+	MaxProbes int64
+}
+
+func (e *HalfOpenProbesExceededError) Error() string {
+	return fmt.Sprintf("max number of half-open probes(%d) exceeded", e.MaxProbes)
+}
+
+// HalfOpenRampRejectedError is an error type for rejecting the invocations
+// above the admitted percentage of the half-open ramp
+type HalfOpenRampRejectedError struct {
+	Percent float64
+}
+
+func (e *HalfOpenRampRejectedError) Error() string {
+	return fmt.Sprintf("rejected by half-open ramp admitting %.2f%% of invocations", e.Percent)
+}
+
+// ShutdownError is an error type for rejecting the invocations after the
+// circuit breaker shutdown
+type ShutdownError struct {
+	Name string
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("circuit breaker(%s) is shut down", e.Name)
+}
+
 // InvocationError is an error type to wrap invocation errors
 type InvocationError struct {
 	Name string
@@ -71,21 +101,82 @@ func (e *InvocationError) Unwrap() error {
 	return e.Err
 }
 
-// InvocationTimeoutError is a error type for invocation timeouts
+// InvocationTimeoutError is a error type for invocation timeouts, it wraps the
+// context error when the caller cancels the invocation before the timeout
 type InvocationTimeoutError struct {
 	Duration time.Duration
+	Err      error
 }
 
 func (e *InvocationTimeoutError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf(
+			"invocation cancelled before timeout on %s: %s",
+			e.Duration,
+			e.Err,
+		)
+	}
+
 	return fmt.Sprintf(
 		"invocation timeout on %s",
 		e.Duration,
 	)
 }
 
-// FailureThresholdReachedError is a error type for failure threshold
-type FailureThresholdReachedError struct{}
+func (e *InvocationTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// FailureThresholdReachedError is a error type for failure threshold, it wraps
+// the last invocation error which caused reaching the threshold
+type FailureThresholdReachedError struct {
+	Err error
+}
 
 func (e *FailureThresholdReachedError) Error() string {
 	return "failure threshold reached"
 }
+
+func (e *FailureThresholdReachedError) Unwrap() error {
+	return e.Err
+}
+
+// SlowCallThresholdReachedError is a error type for slow call threshold
+type SlowCallThresholdReachedError struct {
+	Ratio float32
+}
+
+func (e *SlowCallThresholdReachedError) Error() string {
+	return fmt.Sprintf("slow call threshold reached with %.2f%% ratio", e.Ratio)
+}
+
+// PolicyTrippedError is a error type for the trip policies tripping to 'open'
+// state without a reason on the outcomes other than failure
+type PolicyTrippedError struct {
+	Outcome Outcome
+}
+
+func (e *PolicyTrippedError) Error() string {
+	return fmt.Sprintf("trip policy tripped on %s outcome", e.Outcome)
+}
+
+// OperatorPanicError is an error type for the panics of the operators, the
+// panics are counted as failures and re-raised on the caller goroutine
+type OperatorPanicError struct {
+	Value interface{}
+}
+
+func (e *OperatorPanicError) Error() string {
+	return fmt.Sprintf("operator panicked with %v", e.Value)
+}
+
+// ResultTypeMismatchError is an error type for the typed invocations when the
+// result, e.g. of a fallback, isn't the expected type
+type ResultTypeMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ResultTypeMismatchError) Error() string {
+	return fmt.Sprintf("result type mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
@@ -1,6 +1,7 @@
 package shift
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -62,11 +63,74 @@ func TestInvocationTimeoutError(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "invocation timeout on 5s")
+	assert.Nil(t, errors.Unwrap(err))
+
+	t.Run("with cancellation", func(t *testing.T) {
+		err := &InvocationTimeoutError{
+			Duration: 5 * time.Second,
+			Err:      context.Canceled,
+		}
+
+		assert.EqualError(t, err, "invocation cancelled before timeout on 5s: context canceled")
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
 }
 
 func TestFailureThresholdReachedError(t *testing.T) {
-	err := &FailureThresholdReachedError{}
+	err := &FailureThresholdReachedError{
+		Err: &InvocationTimeoutError{Duration: 5 * time.Second},
+	}
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "failure threshold reached")
+	assert.EqualError(t, errors.Unwrap(err), "invocation timeout on 5s")
+}
+
+func TestSlowCallThresholdReachedError(t *testing.T) {
+	err := &SlowCallThresholdReachedError{Ratio: 62.5}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "slow call threshold reached with 62.50% ratio")
+}
+
+func TestPolicyTrippedError(t *testing.T) {
+	err := &PolicyTrippedError{Outcome: OutcomeSuccess}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "trip policy tripped on success outcome")
+}
+
+func TestHalfOpenProbesExceededError(t *testing.T) {
+	err := &HalfOpenProbesExceededError{MaxProbes: 3}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "max number of half-open probes(3) exceeded")
+}
+
+func TestHalfOpenRampRejectedError(t *testing.T) {
+	err := &HalfOpenRampRejectedError{Percent: 25.0}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "rejected by half-open ramp admitting 25.00% of invocations")
+}
+
+func TestShutdownError(t *testing.T) {
+	err := &ShutdownError{Name: "test"}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "circuit breaker(test) is shut down")
+}
+
+func TestOperatorPanicError(t *testing.T) {
+	err := &OperatorPanicError{Value: "boom"}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "operator panicked with boom")
+}
+
+func TestResultTypeMismatchError(t *testing.T) {
+	err := &ResultTypeMismatchError{Expected: "string", Actual: "int"}
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "result type mismatch: expected string, got int")
 }
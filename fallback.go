@@ -0,0 +1,25 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "context"
+
+// Fallback is an interface for operations which substitute the results of the
+// rejected, timed out and failed invocations
+type Fallback interface {
+	// Execute receives the invocation error and returns a substitute result.
+	// The error is an InvocationError which wraps the cause like
+	// IsOnOpenStateError, InvocationTimeoutError, a restrictor error or the
+	// operator error, so the cause can be classified with errors.As.
+	Execute(context.Context, error) (interface{}, error)
+}
+
+// FallbackFunc is a function that runs the fallback operation
+type FallbackFunc func(context.Context, error) (interface{}, error)
+
+// Execute implements Fallback interface for any FallbackFunc function for free
+func (fn FallbackFunc) Execute(ctx context.Context, err error) (interface{}, error) {
+	return fn(ctx, err)
+}
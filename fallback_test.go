@@ -0,0 +1,29 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackFunc(t *testing.T) {
+	// Ensure FallbackFunc implements Fallback on build
+	var _ Fallback = (FallbackFunc)(nil)
+
+	cause := errors.New("failed")
+	var fn FallbackFunc = func(_ context.Context, err error) (interface{}, error) {
+		assert.Equal(t, cause, err)
+		return "cached", nil
+	}
+
+	res, err := fn.Execute(context.Background(), cause)
+	assert.NoError(t, err)
+	assert.Equal(t, "cached", res)
+}
+
+func TestNilFallbackFunc(t *testing.T) {
+	var fn FallbackFunc
+	assert.Panics(t, func() { _, _ = fn.Execute(context.Background(), nil) })
+}
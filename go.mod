@@ -1,8 +1,14 @@
 module github.com/mustafaturan/shift
 
-go 1.14
+go 1.18
 
 require (
 	github.com/golang/mock v1.4.3
 	github.com/stretchr/testify v1.5.1
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,389 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+)
+
+// Template builds the options of the circuit breaker for the given key. The
+// template is called once per key, so the stateful components like counters,
+// timers and restrictors are not shared between the circuit breakers.
+type Template func(key string) []Option
+
+// GroupOption is a type for circuit breaker group options
+type GroupOption func(*Group) error
+
+// Group is a registry of circuit breakers per key which lazily inits the
+// circuit breakers from a shared option template, so each key like a host or a
+// tenant trips independently
+type Group struct {
+	mutex sync.RWMutex
+
+	// Name is an identity for the group, the circuit breakers are named with
+	// the group name and their keys
+	name string
+
+	// Template builds options for the circuit breakers
+	template Template
+
+	// Breakers holds the circuit breakers per key
+	breakers map[string]*groupEntry
+
+	// MaxKeys is the max number of circuit breakers in the group, the least
+	// recently used circuit breaker is evicted to add a new one
+	maxKeys int
+
+	// IdleTimeout is the duration to evict the unused circuit breakers
+	idleTimeout time.Duration
+
+	// Clock is the time source for the idle circuit breaker eviction
+	clock clock.Clock
+
+	// SweptAt is the last time of the idle circuit breaker eviction
+	sweptAt time.Time
+
+	// StateChangeHandlers are callbacks which called on every state changes
+	// of the circuit breakers with their keys
+	stateChangeHandlers []GroupStateChangeHandler
+
+	// Shutdown rejects the new circuit breakers when set
+	shutdown bool
+
+	// Retiring tracks the in-flight invocations of the stopped circuit
+	// breakers including the evicted ones to wait on shutdown
+	retiring sync.WaitGroup
+}
+
+type groupEntry struct {
+	breaker *Shift
+
+	// usedAt is the last usage time in unix nanoseconds
+	usedAt int64
+}
+
+const (
+	// optionDefaultGroupMaxKeys default max number of keys for groups
+	optionDefaultGroupMaxKeys = 1024
+
+	// optionDefaultGroupIdleTimeout default idle timeout for group keys
+	optionDefaultGroupIdleTimeout = 10 * time.Minute
+)
+
+// NewGroup inits a new circuit breaker group with given name, template and
+// options
+func NewGroup(name string, template Template, opts ...GroupOption) (*Group, error) {
+	if template == nil {
+		return nil, &InvalidOptionError{
+			Name:    "group template",
+			Message: "can't be nil",
+		}
+	}
+
+	g := &Group{
+		name:                name,
+		template:            template,
+		breakers:            make(map[string]*groupEntry),
+		maxKeys:             optionDefaultGroupMaxKeys,
+		idleTimeout:         optionDefaultGroupIdleTimeout,
+		clock:               clock.NewSystemClock(),
+		stateChangeHandlers: make([]GroupStateChangeHandler, 0),
+	}
+
+	for _, opt := range opts {
+		err := opt(g)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g.sweptAt = g.clock.Now()
+	return g, nil
+}
+
+// WithGroupMaxKeys builds option to set max number of keys in the group
+func WithGroupMaxKeys(max int) GroupOption {
+	return func(g *Group) error {
+		if max < 1 {
+			return &InvalidOptionError{
+				Name:    "group max keys",
+				Message: "must be positive int",
+			}
+		}
+		g.maxKeys = max
+		return nil
+	}
+}
+
+// WithGroupIdleTimeout builds option to set idle timeout to evict the unused
+// circuit breakers
+func WithGroupIdleTimeout(duration time.Duration) GroupOption {
+	return func(g *Group) error {
+		if duration <= 0 {
+			return &InvalidOptionError{
+				Name:    "group idle timeout",
+				Message: "must be positive duration",
+			}
+		}
+		g.idleTimeout = duration
+		return nil
+	}
+}
+
+// WithGroupClock builds option to set the time source for the idle circuit
+// breaker eviction, the circuit breakers use their own clock options
+func WithGroupClock(c clock.Clock) GroupOption {
+	return func(g *Group) error {
+		if c == nil {
+			return &InvalidOptionError{
+				Name:    "group clock",
+				Message: "can't be nil",
+			}
+		}
+		g.clock = c
+		return nil
+	}
+}
+
+// WithGroupStateChangeHandlers builds option to set state change handlers, the
+// provided handlers will be evaluate in the given order as option
+func WithGroupStateChangeHandlers(handlers ...GroupStateChangeHandler) GroupOption {
+	return func(g *Group) error {
+		for _, h := range handlers {
+			if h == nil {
+				return &InvalidOptionError{
+					Name:    "group on state change handler",
+					Message: "can't be nil",
+				}
+			}
+		}
+		g.stateChangeHandlers = handlers
+		return nil
+	}
+}
+
+// Run executes the given func with the circuit breaker of the given key, it
+// retries with a new circuit breaker when the circuit breaker of the key is
+// evicted before the invocation
+func (g *Group) Run(ctx context.Context, key string, o Operator) (interface{}, error) {
+	for {
+		s, err := g.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := s.Run(ctx, o)
+		if _, ok := err.(*ShutdownError); ok && !g.isShutdown() {
+			continue
+		}
+		return res, err
+	}
+}
+
+// Get returns the circuit breaker of the given key, inits a new one if there
+// is no circuit breaker for the key
+func (g *Group) Get(key string) (*Shift, error) {
+	now := g.clock.Now()
+
+	// Mark the usage under the lock, so the evictions don't pick the circuit
+	// breaker before its usage is marked
+	g.mutex.RLock()
+	e, ok := g.breakers[key]
+	if ok {
+		atomic.StoreInt64(&e.usedAt, now.UnixNano())
+	}
+	sweep := now.Sub(g.sweptAt) >= g.idleTimeout
+	g.mutex.RUnlock()
+
+	if ok {
+		if sweep {
+			g.mutex.Lock()
+			g.evictIdle(now)
+			g.mutex.Unlock()
+		}
+		return e.breaker, nil
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.shutdown {
+		return nil, &ShutdownError{Name: g.name}
+	}
+
+	// Another goroutine might have already inited the circuit breaker
+	if e, ok := g.breakers[key]; ok {
+		atomic.StoreInt64(&e.usedAt, now.UnixNano())
+		return e.breaker, nil
+	}
+
+	g.evictIdle(now)
+	if len(g.breakers) >= g.maxKeys {
+		g.evictLeastRecentlyUsed()
+	}
+
+	s, err := New(g.name+"/"+key, g.template(key)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var handler OnStateChange = func(from, to State, stats Stats) {
+		g.runStateChangeCallbacks(key, from, to, stats)
+	}
+	s.stateChangeHandlers = append(s.stateChangeHandlers, handler)
+
+	g.breakers[key] = &groupEntry{breaker: s, usedAt: now.UnixNano()}
+	return s, nil
+}
+
+// Remove removes the circuit breaker of the given key from the group and
+// stops it, the removed circuit breaker rejects the new invocations with
+// ShutdownError
+func (g *Group) Remove(key string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.remove(key)
+}
+
+// Keys returns the sorted keys of the circuit breakers in the group
+func (g *Group) Keys() []string {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	keys := make([]string, 0, len(g.breakers))
+	for key := range g.breakers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Range calls the given func for each circuit breaker in the group in the key
+// order until the func returns false
+func (g *Group) Range(fn func(key string, s *Shift) bool) {
+	for _, key := range g.Keys() {
+		g.mutex.RLock()
+		e, ok := g.breakers[key]
+		g.mutex.RUnlock()
+
+		if !ok {
+			continue
+		}
+		if !fn(key, e.breaker) {
+			return
+		}
+	}
+}
+
+// Len returns the number of circuit breakers in the group
+func (g *Group) Len() int {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return len(g.breakers)
+}
+
+// Shutdown shuts down all circuit breakers in the group and waits for their
+// in-flight invocations, including the ones of the evicted and removed circuit
+// breakers, until the given context is done. The group doesn't init new
+// circuit breakers after the shutdown.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mutex.Lock()
+	if !g.shutdown {
+		// Stop all circuit breakers before waiting any of them
+		for _, e := range g.breakers {
+			g.retire(e.breaker)
+		}
+		g.shutdown = true
+	}
+	g.mutex.Unlock()
+
+	waited := make(chan struct{})
+	go func() {
+		g.retiring.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// evictIdle removes the circuit breakers unused longer than the idle timeout
+func (g *Group) evictIdle(now time.Time) {
+	threshold := now.Add(-g.idleTimeout).UnixNano()
+	for key, e := range g.breakers {
+		if atomic.LoadInt64(&e.usedAt) < threshold {
+			g.remove(key)
+		}
+	}
+	g.sweptAt = now
+}
+
+// evictLeastRecentlyUsed removes the least recently used circuit breaker
+func (g *Group) evictLeastRecentlyUsed() {
+	var lruKey string
+	var lruAt int64
+	var found bool
+	for key, e := range g.breakers {
+		usedAt := atomic.LoadInt64(&e.usedAt)
+		if !found || usedAt < lruAt {
+			lruKey, lruAt, found = key, usedAt, true
+		}
+	}
+	g.remove(lruKey)
+}
+
+// remove removes the circuit breaker of the given key and retires it
+func (g *Group) remove(key string) bool {
+	e, ok := g.breakers[key]
+	if !ok {
+		return false
+	}
+
+	delete(g.breakers, key)
+
+	// The circuit breakers are already retired on shutdown
+	if !g.shutdown {
+		g.retire(e.breaker)
+	}
+	return true
+}
+
+// retire stops the given circuit breaker to release its reset timer and
+// health checks, and tracks its in-flight invocations for the shutdown
+func (g *Group) retire(s *Shift) {
+	s.stop()
+
+	g.retiring.Add(1)
+	go func() {
+		defer g.retiring.Done()
+		s.inflight.Wait()
+	}()
+}
+
+// isShutdown checks if the group is shut down
+func (g *Group) isShutdown() bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.shutdown
+}
+
+func (g *Group) runStateChangeCallbacks(key string, from, to State, stats Stats) {
+	for _, h := range g.stateChangeHandlers {
+		h.Handle(key, from, to, stats)
+	}
+}
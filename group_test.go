@@ -0,0 +1,464 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGroup(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+
+	t.Run("with nil template", func(t *testing.T) {
+		g, err := NewGroup(name, nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with invalid option", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupMaxKeys(0))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		g, err := NewGroup(name, template)
+		assert.NoError(t, err)
+		assert.NotNil(t, g)
+		assert.Equal(t, name, g.name)
+		assert.Equal(t, optionDefaultGroupMaxKeys, g.maxKeys)
+		assert.Equal(t, optionDefaultGroupIdleTimeout, g.idleTimeout)
+		assert.Equal(t, 0, len(g.stateChangeHandlers))
+		assert.Equal(t, 0, g.Len())
+	})
+}
+
+func TestWithGroupMaxKeys(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+
+	t.Run("with invalid max keys", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupMaxKeys(-1))
+		assert.Error(t, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with valid max keys", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupMaxKeys(3))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, g.maxKeys)
+	})
+}
+
+func TestWithGroupIdleTimeout(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+
+	t.Run("with invalid idle timeout", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupIdleTimeout(0))
+		assert.Error(t, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with valid idle timeout", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupIdleTimeout(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, g.idleTimeout)
+	})
+}
+
+func TestWithGroupClock(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+
+	t.Run("with a nil clock", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupClock(nil))
+		assert.Error(t, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with valid clock", func(t *testing.T) {
+		now := time.Now()
+		clk := clocktest.NewClock(now)
+		g, err := NewGroup(name, template, WithGroupClock(clk))
+		assert.NoError(t, err)
+		assert.Equal(t, clk, g.clock)
+		assert.Equal(t, now, g.sweptAt)
+	})
+}
+
+func TestWithGroupStateChangeHandlers(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+
+	t.Run("with a nil state change handler", func(t *testing.T) {
+		var validHandler OnGroupStateChange = func(string, State, State, Stats) {}
+		var nilHandler GroupStateChangeHandler
+		g, err := NewGroup(name, template, WithGroupStateChangeHandlers(validHandler, nilHandler))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler OnGroupStateChange = func(string, State, State, Stats) {}
+		g, err := NewGroup(name, template, WithGroupStateChangeHandlers(handler, handler))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(g.stateChangeHandlers))
+	})
+}
+
+func TestGroup_Get(t *testing.T) {
+	t.Run("inits lazily per key", func(t *testing.T) {
+		var keys []string
+		var template Template = func(key string) []Option {
+			keys = append(keys, key)
+			return []Option{WithInvocationTimeout(time.Second)}
+		}
+		g, err := NewGroup(name, template)
+		require.NoError(t, err)
+
+		s1, err := g.Get("a")
+		assert.NoError(t, err)
+		assert.Equal(t, "test/a", s1.name)
+
+		s2, err := g.Get("a")
+		assert.NoError(t, err)
+		assert.Same(t, s1, s2)
+
+		s3, err := g.Get("b")
+		assert.NoError(t, err)
+		assert.NotSame(t, s1, s3)
+
+		assert.Equal(t, []string{"a", "b"}, keys)
+	})
+
+	t.Run("with invalid template options", func(t *testing.T) {
+		var template Template = func(string) []Option {
+			return []Option{WithOpener(StateOpen, 90.0, 10)}
+		}
+		g, err := NewGroup(name, template)
+		require.NoError(t, err)
+
+		s, err := g.Get("a")
+		assert.Error(t, err)
+		assert.Nil(t, s)
+		assert.Equal(t, 0, g.Len())
+	})
+
+	t.Run("evicts the least recently used on max keys", func(t *testing.T) {
+		var template Template = func(string) []Option { return nil }
+		clk := clocktest.NewClock(time.Now())
+		g, err := NewGroup(name, template, WithGroupMaxKeys(2), WithGroupClock(clk))
+		require.NoError(t, err)
+
+		_, _ = g.Get("a")
+		clk.Advance(time.Millisecond)
+		_, _ = g.Get("b")
+		clk.Advance(time.Millisecond)
+		_, _ = g.Get("a")
+		clk.Advance(time.Millisecond)
+		_, _ = g.Get("c")
+
+		assert.Equal(t, []string{"a", "c"}, g.Keys())
+	})
+
+	t.Run("evicts the idle keys", func(t *testing.T) {
+		var template Template = func(string) []Option { return nil }
+		clk := clocktest.NewClock(time.Now())
+		g, err := NewGroup(
+			name,
+			template,
+			WithGroupIdleTimeout(time.Minute),
+			WithGroupClock(clk),
+		)
+		require.NoError(t, err)
+
+		_, _ = g.Get("a")
+		_, _ = g.Get("b")
+		clk.Advance(time.Minute + time.Second)
+		_, _ = g.Get("b")
+
+		assert.Equal(t, []string{"b"}, g.Keys())
+	})
+
+	t.Run("concurrent inits", func(t *testing.T) {
+		var template Template = func(string) []Option { return nil }
+		g, err := NewGroup(name, template)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		breakers := make([]*Shift, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				breakers[i], _ = g.Get("a")
+			}(i)
+		}
+		wg.Wait()
+
+		for _, s := range breakers {
+			assert.Same(t, breakers[0], s)
+		}
+	})
+}
+
+func TestGroup_Run(t *testing.T) {
+	var template Template = func(string) []Option {
+		return []Option{WithOpener(StateClose, 90.0, 1)}
+	}
+
+	var mutex sync.Mutex
+	var changes []string
+	var handler OnGroupStateChange = func(key string, from, to State, _ Stats) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		changes = append(changes, key+":"+from.String()+"->"+to.String())
+	}
+
+	g, err := NewGroup(name, template, WithGroupStateChangeHandlers(handler))
+	require.NoError(t, err)
+
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+	var failure Operate = func(context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+
+	res, err := g.Run(context.Background(), "a", success)
+	assert.NoError(t, err)
+	assert.Equal(t, "welldone", res)
+
+	_, err = g.Run(context.Background(), "b", failure)
+	assert.Error(t, err)
+
+	a, _ := g.Get("a")
+	b, _ := g.Get("b")
+	assert.Equal(t, StateClose, a.currentState())
+	assert.Equal(t, StateOpen, b.currentState())
+	assert.Equal(t, []string{"b:close->open"}, changes)
+
+	t.Run("with invalid template options", func(t *testing.T) {
+		var template Template = func(string) []Option {
+			return []Option{WithOpener(StateOpen, 90.0, 10)}
+		}
+		g, err := NewGroup(name, template)
+		require.NoError(t, err)
+
+		res, err := g.Run(context.Background(), "a", success)
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestGroup_RunWithEvictions(t *testing.T) {
+	var template Template = func(string) []Option {
+		return nil
+	}
+
+	g, err := NewGroup(name, template, WithGroupMaxKeys(1))
+	require.NoError(t, err)
+
+	var o Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	// The keys churn on the max keys, so the circuit breakers are evicted
+	// while the other goroutines run them in parallel
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16*100)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := string(rune('a' + (i+j)%2))
+				if _, err := g.Run(context.Background(), key, o); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, g.Len())
+
+	t.Run("after shutdown", func(t *testing.T) {
+		require.NoError(t, g.Shutdown(context.Background()))
+
+		_, err := g.Run(context.Background(), "a", o)
+		assert.IsType(t, &ShutdownError{}, err)
+	})
+}
+
+func TestGroup_Remove(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+	g, err := NewGroup(name, template)
+	require.NoError(t, err)
+
+	_, _ = g.Get("a")
+	assert.True(t, g.Remove("a"))
+	assert.False(t, g.Remove("a"))
+	assert.Equal(t, 0, g.Len())
+}
+
+func TestGroup_Eviction(t *testing.T) {
+	clk := clocktest.NewClock(time.Now())
+	var template Template = func(string) []Option {
+		return []Option{WithClock(clk)}
+	}
+
+	// opens the circuit breaker of the given key to schedule its reset timer
+	open := func(t *testing.T, g *Group, key string) *Shift {
+		s, err := g.Get(key)
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+		return s
+	}
+
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	tests := []struct {
+		name  string
+		opts  []GroupOption
+		evict func(g *Group)
+	}{
+		{
+			name:  "on remove",
+			evict: func(g *Group) { g.Remove("a") },
+		},
+		{
+			name: "on max keys",
+			opts: []GroupOption{WithGroupMaxKeys(1)},
+			evict: func(g *Group) {
+				_, _ = g.Get("b")
+			},
+		},
+		{
+			name: "on idle timeout",
+			opts: []GroupOption{WithGroupIdleTimeout(time.Minute)},
+			evict: func(g *Group) {
+				clk.Advance(time.Minute + time.Second)
+				_, _ = g.Get("b")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]GroupOption{WithGroupClock(clk)}, test.opts...)
+			g, err := NewGroup(name, template, opts...)
+			require.NoError(t, err)
+
+			s := open(t, g, "a")
+			require.Equal(t, 1, clk.Timers())
+
+			// the evicted circuit breaker releases its reset timer
+			test.evict(g)
+			assert.NotContains(t, g.Keys(), "a")
+			assert.Equal(t, 0, clk.Timers())
+
+			_, err = s.Run(context.Background(), success)
+			assert.IsType(t, &ShutdownError{}, err)
+		})
+	}
+}
+
+func TestGroup_Range(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+	g, err := NewGroup(name, template)
+	require.NoError(t, err)
+
+	for _, key := range []string{"c", "a", "b"} {
+		_, _ = g.Get(key)
+	}
+
+	t.Run("iterates in key order", func(t *testing.T) {
+		var keys []string
+		g.Range(func(key string, s *Shift) bool {
+			assert.Equal(t, "test/"+key, s.name)
+			keys = append(keys, key)
+			return true
+		})
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+	})
+
+	t.Run("stops on false", func(t *testing.T) {
+		var keys []string
+		g.Range(func(key string, _ *Shift) bool {
+			keys = append(keys, key)
+			return false
+		})
+		assert.Equal(t, []string{"a"}, keys)
+	})
+}
+
+func TestGroup_Shutdown(t *testing.T) {
+	var template Template = func(string) []Option { return nil }
+	g, err := NewGroup(name, template)
+	require.NoError(t, err)
+
+	a, _ := g.Get("a")
+	b, _ := g.Get("b")
+	require.NoError(t, g.Shutdown(context.Background()))
+
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+	for _, s := range []*Shift{a, b} {
+		_, err := s.Run(context.Background(), success)
+		assert.IsType(t, &ShutdownError{}, err)
+	}
+
+	t.Run("rejects new keys", func(t *testing.T) {
+		s, err := g.Get("c")
+		assert.Nil(t, s)
+		assert.Equal(t, &ShutdownError{Name: name}, err)
+	})
+
+	t.Run("waits for the evicted circuit breakers", func(t *testing.T) {
+		g, err := NewGroup(name, template, WithGroupMaxKeys(1))
+		require.NoError(t, err)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		var blocking Operate = func(context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return "welldone", nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = g.Run(context.Background(), "a", blocking)
+		}()
+		<-started
+
+		// evicts the circuit breaker with the in-flight invocation
+		_, _ = g.Get("b")
+		require.Equal(t, []string{"b"}, g.Keys())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, context.Canceled, g.Shutdown(ctx))
+
+		close(release)
+		<-done
+		assert.NoError(t, g.Shutdown(context.Background()))
+	})
+}
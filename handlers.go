@@ -35,6 +35,34 @@ func (fn OnSuccess) Handle(ctx context.Context, res interface{}) {
 	fn(ctx, res)
 }
 
+// CustomOutcomeHandler is an interface to handle the invocations labelled with
+// the custom outcomes by the error classifiers
+type CustomOutcomeHandler interface {
+	Handle(context.Context, Outcome, error)
+}
+
+// OnCustomOutcome is a function to run on any invocation with a custom outcome
+type OnCustomOutcome func(context.Context, Outcome, error)
+
+// Handle implements CustomOutcomeHandler for OnCustomOutcome func
+func (fn OnCustomOutcome) Handle(ctx context.Context, outcome Outcome, err error) {
+	fn(ctx, outcome, err)
+}
+
+// PanicHandler is an interface to handle the panics of the operators which
+// happen after their invocations are timed out or cancelled
+type PanicHandler interface {
+	Handle(value interface{})
+}
+
+// OnPanic is a function to run on any late panic of the operators
+type OnPanic func(value interface{})
+
+// Handle implements PanicHandler for OnPanic func
+func (fn OnPanic) Handle(value interface{}) {
+	fn(value)
+}
+
 // StateChangeHandler is an interface to handle state change events
 type StateChangeHandler interface {
 	Handle(from, to State, stats Stats)
@@ -47,3 +75,18 @@ type OnStateChange func(from, to State, stats Stats)
 func (fn OnStateChange) Handle(from, to State, stats Stats) {
 	fn(from, to, stats)
 }
+
+// GroupStateChangeHandler is an interface to handle state change events of the
+// circuit breakers in a group
+type GroupStateChangeHandler interface {
+	Handle(key string, from, to State, stats Stats)
+}
+
+// OnGroupStateChange is a function to run on any state changes of the circuit
+// breakers in a group
+type OnGroupStateChange func(key string, from, to State, stats Stats)
+
+// Handle implements GroupStateChangeHandler for OnGroupStateChange func
+func (fn OnGroupStateChange) Handle(key string, from, to State, stats Stats) {
+	fn(key, from, to, stats)
+}
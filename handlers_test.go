@@ -20,6 +20,19 @@ func TestOnFailure(t *testing.T) {
 	assert.Equal(t, true, called)
 }
 
+func TestOnCustomOutcome(t *testing.T) {
+	// Ensure OnCustomOutcome implements CustomOutcomeHandler on build
+	var _ CustomOutcomeHandler = (OnCustomOutcome)(nil)
+
+	var called Outcome
+	var fn OnCustomOutcome = func(_ context.Context, outcome Outcome, _ error) {
+		called = outcome
+	}
+
+	fn.Handle(context.Background(), "client_error", nil)
+	assert.Equal(t, Outcome("client_error"), called)
+}
+
 func TestOnSuccess(t *testing.T) {
 	// Ensure OnSuccess implements SuccessHandler on build
 	var _ SuccessHandler = (OnSuccess)(nil)
@@ -45,3 +58,29 @@ func TestOnStateChange(t *testing.T) {
 	fn.Handle(StateClose, StateOpen, Stats{})
 	assert.Equal(t, true, called)
 }
+
+func TestOnPanic(t *testing.T) {
+	// Ensure OnPanic implements PanicHandler on build
+	var _ PanicHandler = (OnPanic)(nil)
+
+	var value interface{}
+	var fn OnPanic = func(v interface{}) {
+		value = v
+	}
+
+	fn.Handle("boom")
+	assert.Equal(t, "boom", value)
+}
+
+func TestOnGroupStateChange(t *testing.T) {
+	// Ensure OnGroupStateChange implements GroupStateChangeHandler on build
+	var _ GroupStateChangeHandler = (OnGroupStateChange)(nil)
+
+	var called bool
+	var fn OnGroupStateChange = func(string, State, State, Stats) {
+		called = true
+	}
+
+	fn.Handle("key", StateClose, StateOpen, Stats{})
+	assert.Equal(t, true, called)
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import (
+	"context"
+	"time"
+)
+
+// healthCheck holds the synthetic probe configuration to recover from the
+// open state without using the live invocations as probes
+type healthCheck struct {
+	operator  Operator
+	interval  time.Duration
+	successes uint32
+	target    State
+}
+
+// recover trips the circuit breaker to half-open state or starts the health
+// checks if configured when the reset timer fires
+func (s *Shift) recover(opening uint64) {
+	if s.healthCheck == nil {
+		_ = s.Trip(StateHalfOpen)
+		return
+	}
+
+	if !s.enter() {
+		return
+	}
+	go func() {
+		defer s.leave()
+		s.runHealthCheck(opening)
+	}()
+}
+
+// runHealthCheck probes with the health check operator on every interval until
+// the consecutive successful probes reach the configured number. It stops when
+// the circuit breaker leaves the open state of the given opening.
+func (s *Shift) runHealthCheck(opening uint64) {
+	hc := s.healthCheck
+	i := &deadlineInvoker{
+		timeout:         s.invokers[StateClose].(*onCloseInvoker).timeout,
+		timeoutCallback: func(context.Context) {},
+		clock:           s.clock,
+		inflight:        &s.inflight,
+	}
+
+	timer := s.clock.NewTimer(hc.interval)
+	defer timer.Stop()
+
+	var successes uint32
+	for {
+		if s.currentOpening() != opening {
+			return
+		}
+
+		if _, err := i.invoke(context.Background(), hc.operator); err != nil {
+			successes = 0
+		} else {
+			successes++
+		}
+
+		if successes >= hc.successes {
+			if s.currentOpening() == opening {
+				_ = s.Trip(hc.target)
+			}
+			return
+		}
+
+		select {
+		case <-timer.C():
+			timer.Reset(hc.interval)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// currentOpening returns the number of openings of the circuit breaker if it is
+// on open state, otherwise zero
+func (s *Shift) currentOpening() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if !s.state.isOpen() {
+		return 0
+	}
+	return s.openings
+}
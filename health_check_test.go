@@ -0,0 +1,96 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mustafaturan/shift/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("without health check", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(time.Millisecond)
+
+		s, err := New(name, WithResetTimer(timer))
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+
+		require.Eventually(t, func() bool {
+			return s.currentState() == StateHalfOpen
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("with health check", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(time.Millisecond)
+		timer.EXPECT().Reset()
+
+		var probes int32
+		var probe Operate = func(context.Context) (interface{}, error) {
+			// the second probe fails and breaks the streak
+			if atomic.AddInt32(&probes, 1) == 2 {
+				return nil, errors.New("unhealthy")
+			}
+			return "healthy", nil
+		}
+
+		s, err := New(
+			name,
+			WithResetTimer(timer),
+			WithHealthCheck(probe, time.Millisecond, 3, StateClose),
+		)
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+
+		require.Eventually(t, func() bool {
+			return s.currentState() == StateClose
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, int32(5), atomic.LoadInt32(&probes))
+
+		stats := s.stats()
+		assert.Equal(t, uint32(0), stats.SuccessCount)
+		assert.Equal(t, uint32(0), stats.FailureCount)
+	})
+
+	t.Run("with health check on stale opening", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(time.Millisecond)
+
+		var probes int32
+		var probe Operate = func(context.Context) (interface{}, error) {
+			atomic.AddInt32(&probes, 1)
+			return nil, errors.New("unhealthy")
+		}
+
+		s, err := New(
+			name,
+			WithResetTimer(timer),
+			WithHealthCheck(probe, time.Millisecond, 1, StateHalfOpen),
+		)
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&probes) > 0
+		}, time.Second, time.Millisecond)
+
+		// Stops probing when the circuit breaker leaves the open state
+		require.NoError(t, s.Trip(StateHalfOpen))
+		time.Sleep(5 * time.Millisecond)
+		stopped := atomic.LoadInt32(&probes)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, stopped, atomic.LoadInt32(&probes))
+		assert.Equal(t, StateHalfOpen, s.currentState())
+	})
+}
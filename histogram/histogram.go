@@ -0,0 +1,248 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+// Package histogram records durations into compact and mergeable log buckets
+// to summarize the invocation latencies of the circuit breakers
+package histogram
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// Resolution is the finest recorded duration, the shorter durations are
+	// recorded as zero
+	Resolution = time.Microsecond
+
+	// MaxDuration is the longest recorded duration, the longer durations are
+	// recorded as MaxDuration
+	MaxDuration = time.Duration(math.MaxUint32) * Resolution
+
+	// subBits is the number of the linear sub buckets per power of two in
+	// bits, the values are bucketed with a relative error under 1/16
+	subBits  = 4
+	subCount = 1 << subBits
+
+	// maxBits is the bit length of the longest recorded value
+	maxBits = 32
+
+	groupCount  = maxBits - subBits + 1
+	bucketCount = groupCount * subCount
+)
+
+// Summary is the percentiles, max and mean of the recorded durations, the
+// percentiles and max are the upper bounds of their buckets
+type Summary struct {
+	Count                    uint32
+	Mean, P50, P90, P99, Max time.Duration
+}
+
+// Histogram is a log-linear histogram of durations with a fixed number of
+// buckets. The durations are bucketed by their powers of two and the 16
+// linear sub buckets of each power, like HDR histograms. The zero value is an
+// empty histogram ready to use. It isn't safe for the concurrent usages, the
+// owners need to guard it.
+type Histogram struct {
+	counts []uint32
+
+	// groups are the counts per power of two to skip the empty sub buckets
+	// on lookups
+	groups []uint32
+
+	count uint32
+	sum   time.Duration
+}
+
+// Record records the given duration
+func (h *Histogram) Record(d time.Duration) {
+	h.init()
+	d = clamp(d)
+	i := index(d)
+	h.counts[i]++
+	h.groups[i/subCount]++
+	h.count++
+	h.sum += d
+}
+
+// Remove removes the given duration which is previously recorded
+func (h *Histogram) Remove(d time.Duration) {
+	d = clamp(d)
+	i := index(d)
+	if h.counts == nil || h.counts[i] == 0 {
+		return
+	}
+
+	h.counts[i]--
+	h.groups[i/subCount]--
+	h.count--
+	h.sum -= d
+}
+
+// Merge adds the recorded durations of the given histogram
+func (h *Histogram) Merge(o *Histogram) {
+	if o.count == 0 {
+		return
+	}
+
+	h.init()
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	for g, c := range o.groups {
+		h.groups[g] += c
+	}
+	h.count += o.count
+	h.sum += o.sum
+}
+
+// Subtract removes the recorded durations of the given histogram which is
+// previously merged
+func (h *Histogram) Subtract(o *Histogram) {
+	if o.count == 0 || h.counts == nil {
+		return
+	}
+
+	for i, c := range o.counts {
+		h.counts[i] -= c
+	}
+	for g, c := range o.groups {
+		h.groups[g] -= c
+	}
+	h.count -= o.count
+	h.sum -= o.sum
+}
+
+// Reset removes all recorded durations and keeps the buckets for reuse
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	for g := range h.groups {
+		h.groups[g] = 0
+	}
+	h.count = 0
+	h.sum = 0
+}
+
+// Count returns the number of the recorded durations
+func (h *Histogram) Count() uint32 {
+	return h.count
+}
+
+// Mean returns the exact mean of the recorded durations
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Quantile returns the duration which the given quantile(0.0 to 1.0) of the
+// recorded durations are less than or equal to
+func (h *Histogram) Quantile(q float64) time.Duration {
+	return h.find(rank(q, h.count))
+}
+
+// Max returns the longest recorded duration
+func (h *Histogram) Max() time.Duration {
+	return h.find(h.count)
+}
+
+// Summary returns the summary of the recorded durations
+func (h *Histogram) Summary() Summary {
+	return Summary{
+		Count: h.count,
+		Mean:  h.Mean(),
+		P50:   h.Quantile(0.5),
+		P90:   h.Quantile(0.9),
+		P99:   h.Quantile(0.99),
+		Max:   h.Max(),
+	}
+}
+
+// find returns the upper bound of the bucket holding the recorded duration
+// with the given 1-based rank
+func (h *Histogram) find(target uint32) time.Duration {
+	if target == 0 {
+		return 0
+	}
+
+	// Find the group of the rank first, then its sub bucket
+	var seen uint32
+	for g, c := range h.groups {
+		if seen+c < target {
+			seen += c
+			continue
+		}
+
+		for i := g * subCount; i < (g+1)*subCount; i++ {
+			seen += h.counts[i]
+			if seen >= target {
+				return value(i)
+			}
+		}
+	}
+	return 0
+}
+
+func (h *Histogram) init() {
+	if h.counts == nil {
+		h.counts = make([]uint32, bucketCount)
+		h.groups = make([]uint32, groupCount)
+	}
+}
+
+// rank returns the 1-based rank of the given quantile
+func rank(q float64, count uint32) uint32 {
+	if count == 0 {
+		return 0
+	}
+
+	r := uint32(math.Ceil(q * float64(count)))
+	if r < 1 {
+		return 1
+	}
+	if r > count {
+		return count
+	}
+	return r
+}
+
+// clamp limits the given duration into the recorded range
+func clamp(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if d > MaxDuration {
+		return MaxDuration
+	}
+	return d
+}
+
+// index returns the bucket index of the given duration in the recorded range
+func index(d time.Duration) int {
+	v := uint64(d / Resolution)
+	if v < subCount {
+		return int(v)
+	}
+
+	// The power of two selects the bucket group and the following bits
+	// select the linear sub bucket
+	p := bits.Len64(v) - 1
+	return (p-subBits+1)*subCount + int(v>>uint(p-subBits)) - subCount
+}
+
+// value returns the upper bound of the bucket with the given index
+func value(i int) time.Duration {
+	if i < subCount {
+		return time.Duration(i) * Resolution
+	}
+
+	shift := uint(i/subCount - 1)
+	lower := uint64(i%subCount+subCount) << shift
+	upper := lower + 1<<shift - 1
+	return time.Duration(upper) * Resolution
+}
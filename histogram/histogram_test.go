@@ -0,0 +1,169 @@
+package histogram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	var h Histogram
+	h.Record(10 * time.Millisecond)
+	h.Record(20 * time.Millisecond)
+
+	assert.Equal(t, uint32(2), h.Count())
+	assert.Equal(t, 15*time.Millisecond, h.Mean())
+	assert.Equal(t, bucketCount, len(h.counts))
+
+	t.Run("clamps the out of range durations", func(t *testing.T) {
+		var h Histogram
+		h.Record(-time.Second)
+		h.Record(2 * MaxDuration)
+
+		assert.Equal(t, time.Duration(0), h.Quantile(0.5))
+		assert.Equal(t, MaxDuration, h.Max())
+		assert.Equal(t, MaxDuration/2, h.Mean())
+	})
+}
+
+func TestRemove(t *testing.T) {
+	var h Histogram
+	h.Record(10 * time.Millisecond)
+	h.Record(20 * time.Millisecond)
+	h.Remove(20 * time.Millisecond)
+
+	assert.Equal(t, uint32(1), h.Count())
+	assert.Equal(t, 10*time.Millisecond, h.Mean())
+
+	t.Run("skips the unrecorded durations", func(t *testing.T) {
+		h.Remove(time.Second)
+		assert.Equal(t, uint32(1), h.Count())
+
+		var empty Histogram
+		empty.Remove(time.Second)
+		assert.Equal(t, uint32(0), empty.Count())
+	})
+}
+
+func TestMergeAndSubtract(t *testing.T) {
+	var a, b, window Histogram
+	a.Record(time.Millisecond)
+	b.Record(3 * time.Millisecond)
+
+	window.Merge(&a)
+	window.Merge(&b)
+	assert.Equal(t, uint32(2), window.Count())
+	assert.Equal(t, 2*time.Millisecond, window.Mean())
+
+	window.Subtract(&a)
+	assert.Equal(t, uint32(1), window.Count())
+	assert.Equal(t, b.Summary(), window.Summary())
+
+	t.Run("with empty histograms", func(t *testing.T) {
+		var empty, h Histogram
+		h.Merge(&empty)
+		h.Subtract(&a)
+
+		assert.Nil(t, h.counts)
+		assert.Equal(t, uint32(0), h.Count())
+	})
+}
+
+func TestReset(t *testing.T) {
+	var h Histogram
+	h.Record(time.Millisecond)
+	h.Reset()
+
+	assert.Equal(t, uint32(0), h.Count())
+	assert.Equal(t, time.Duration(0), h.Mean())
+	assert.Equal(t, time.Duration(0), h.Max())
+	assert.Equal(t, bucketCount, len(h.counts))
+}
+
+func TestQuantile(t *testing.T) {
+	var h Histogram
+	assert.Equal(t, time.Duration(0), h.Quantile(0.5))
+
+	for i := 1; i <= 100; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.0, time.Millisecond},
+		{0.5, 50 * time.Millisecond},
+		{0.9, 90 * time.Millisecond},
+		{0.99, 99 * time.Millisecond},
+		{1.0, 100 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		got := h.Quantile(test.q)
+		assert.GreaterOrEqual(t, int64(got), int64(test.want))
+		assert.LessOrEqual(t, float64(got-test.want), float64(test.want)/subCount)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var h Histogram
+	assert.Equal(t, Summary{}, h.Summary())
+
+	for i := 1; i <= 100; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	s := h.Summary()
+	assert.Equal(t, uint32(100), s.Count)
+	assert.Equal(t, 50500*time.Microsecond, s.Mean)
+	assert.Equal(t, h.Quantile(0.5), s.P50)
+	assert.Equal(t, h.Quantile(0.9), s.P90)
+	assert.Equal(t, h.Quantile(0.99), s.P99)
+	assert.Equal(t, h.Max(), s.Max)
+}
+
+func TestBuckets(t *testing.T) {
+	t.Run("the exact values under the sub buckets", func(t *testing.T) {
+		for i := 0; i < 2*subCount; i++ {
+			d := time.Duration(i) * Resolution
+			assert.Equal(t, d, value(index(d)))
+		}
+	})
+
+	t.Run("the upper bounds with relative errors under 1/16", func(t *testing.T) {
+		prev := -1
+		for d := 2 * subCount * Resolution; d < MaxDuration; d = (d*9/8 + Resolution).Truncate(Resolution) {
+			i := index(d)
+			assert.True(t, i >= prev && i < bucketCount)
+			assert.GreaterOrEqual(t, int64(value(i)), int64(d))
+			assert.LessOrEqual(t, float64(value(i)-d), float64(d)/subCount)
+			prev = i
+		}
+	})
+
+	t.Run("the last bucket", func(t *testing.T) {
+		assert.Equal(t, bucketCount-1, index(MaxDuration))
+		assert.Equal(t, MaxDuration, value(bucketCount-1))
+	})
+}
+
+func BenchmarkRecord(b *testing.B) {
+	var h Histogram
+	for i := 0; i < b.N; i++ {
+		h.Record(time.Duration(i%1000) * time.Millisecond)
+	}
+}
+
+func BenchmarkSummary(b *testing.B) {
+	var h Histogram
+	for i := 0; i < 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Summary()
+	}
+}
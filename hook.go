@@ -0,0 +1,26 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "github.com/mustafaturan/shift/internal/hook"
+
+func init() {
+	hook.AppendStateChangeHandler = appendStateChangeHandler
+}
+
+// appendStateChangeHandler builds option to append a state change handler
+// which runs after the handlers of the WithStateChangeHandlers option
+func appendStateChangeHandler(h StateChangeHandler) Option {
+	return func(s *Shift) error {
+		if h == nil {
+			return &InvalidOptionError{
+				Name:    "on state change handler",
+				Message: "can't be nil",
+			}
+		}
+		s.hookedStateChangeHandlers = append(s.hookedStateChangeHandlers, h)
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package shift
+
+import (
+	"testing"
+
+	"github.com/mustafaturan/shift/internal/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendStateChangeHandler(t *testing.T) {
+	_, ok := hook.AppendStateChangeHandler.(func(StateChangeHandler) Option)
+	assert.True(t, ok)
+
+	t.Run("with a nil state change handler", func(t *testing.T) {
+		s, err := New(name, appendStateChangeHandler(nil))
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("appends regardless of the option order", func(t *testing.T) {
+		var handler OnStateChange = func(_, _ State, _ Stats) {}
+		s, err := New(
+			name,
+			appendStateChangeHandler(handler),
+			WithStateChangeHandlers(handler, handler),
+			appendStateChangeHandler(handler),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, len(s.stateChangeHandlers))
+		assert.Equal(t, 2, len(s.hookedStateChangeHandlers))
+	})
+}
@@ -0,0 +1,12 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+// Package hook shares the unexported extension points of the shift package
+// with the packages of this module like shifttest
+package hook
+
+// AppendStateChangeHandler builds an option to append a state change handler
+// without replacing the handlers of the other options. It is set by the shift
+// package on init as a func(shift.StateChangeHandler) shift.Option.
+var AppendStateChangeHandler interface{}
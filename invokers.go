@@ -6,7 +6,13 @@ package shift
 
 import (
 	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/mustafaturan/shift/clock"
 )
 
 type invoker interface {
@@ -14,8 +20,21 @@ type invoker interface {
 }
 
 type deadlineInvoker struct {
+	clock           clock.Clock
 	timeout         time.Duration
-	timeoutCallback func()
+	timeoutCallback func(context.Context)
+
+	// slowThreshold is the latency bound for the successful invocations, zero
+	// disables the slow call detection
+	slowThreshold time.Duration
+	slowCallback  func(context.Context)
+
+	// inflight tracks the operators which can outlive their timeouts
+	inflight *sync.WaitGroup
+
+	// latePanicCallback receives the panics of the operators which outlive
+	// their invocations, the panics are logged without the callback
+	latePanicCallback func(interface{})
 }
 
 type onCloseInvoker = deadlineInvoker
@@ -23,19 +42,36 @@ type onCloseInvoker = deadlineInvoker
 type onHalfOpenInvoker = deadlineInvoker
 
 type onOpenInvoker struct {
-	rejectCallback func()
+	rejectCallback func(context.Context)
 }
 
 // invocation is a type for holding invocation result
 type invocation struct {
-	res interface{}
-	err error
+	res      interface{}
+	err      error
+	duration time.Duration
+
+	// panic holds the recovered value if the operator panics
+	panic interface{}
+}
+
+const (
+	callRunning int32 = iota
+	callFinished
+	callAbandoned
+)
+
+// call is an operator execution shared by the invoker and the operator
+// goroutine, the state decides which one owns the result
+type call struct {
+	ch    chan invocation
+	state int32
 }
 
 /* on open state */
 
 func (i *onOpenInvoker) invoke(ctx context.Context, o Operator) (interface{}, error) {
-	i.rejectCallback()
+	i.rejectCallback(ctx)
 	return nil, &IsOnOpenStateError{}
 }
 
@@ -43,36 +79,107 @@ func (i *onOpenInvoker) invoke(ctx context.Context, o Operator) (interface{}, er
 
 func (i *deadlineInvoker) invoke(ctx context.Context, o Operator) (interface{}, error) {
 	var cancel context.CancelFunc
-	ctx, cancel = context.WithTimeout(ctx, i.timeout)
+	ctx, cancel = i.clock.WithTimeout(ctx, i.timeout)
 	defer cancel()
 
+	c := i.async(ctx, o)
 	select {
 	case <-ctx.Done():
-		i.timeoutCallback()
-		return nil, &InvocationTimeoutError{Duration: i.timeout}
-	case i := <-i.async(ctx, o):
-		return i.res, i.err
+		// The operator might finish right before abandoning, then its result
+		// is already on the way
+		if atomic.CompareAndSwapInt32(&c.state, callRunning, callAbandoned) {
+			return i.done(ctx)
+		}
+		return i.result(ctx, <-c.ch)
+	case inv := <-c.ch:
+		return i.result(ctx, inv)
 	}
 }
 
-func (i *deadlineInvoker) async(ctx context.Context, o Operator) chan invocation {
-	// allow putting one invocation result into chan even if noone reads
-	ch := make(chan invocation, 1)
+// result returns the result of the finished invocation
+func (i *deadlineInvoker) result(ctx context.Context, inv invocation) (interface{}, error) {
+	// The panics are returned as errors to count them as failures, the
+	// circuit breaker re-panics on the caller goroutine afterwards
+	if inv.panic != nil {
+		return nil, &OperatorPanicError{Value: inv.panic}
+	}
+
+	// The operator failed due to the done context
+	if inv.err != nil && ctx.Err() != nil {
+		return i.done(ctx)
+	}
 
-	defer func() {
-		go func() {
-			// close the chan right after putting the val into it, since the
-			// receive happens earlier then the put operation it won't cause any
-			// problem
-			defer close(ch)
+	if inv.err == nil && i.isSlow(inv.duration) {
+		i.slowCallback(ctx)
+	}
+	return inv.res, inv.err
+}
 
-			// operator can cancel execution with context timeout too
-			res, err := o.Execute(ctx)
+// done returns the error for the done context
+func (i *deadlineInvoker) done(ctx context.Context) (interface{}, error) {
+	// The cancellations by the caller are not counted as timeouts, the error
+	// keeps its type and wraps the cancellation
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, &InvocationTimeoutError{Duration: i.timeout, Err: ctx.Err()}
+	}
 
-			// even if noone reads, it is non-blocking with the buffered channel
-			ch <- invocation{res: res, err: err}
+	i.timeoutCallback(ctx)
+	return nil, &InvocationTimeoutError{Duration: i.timeout}
+}
+
+// isSlow checks if the given duration exceeds the slow call threshold
+func (i *deadlineInvoker) isSlow(duration time.Duration) bool {
+	return i.slowThreshold > 0 && duration > i.slowThreshold
+}
+
+func (i *deadlineInvoker) async(ctx context.Context, o Operator) *call {
+	// allow putting one invocation result into chan even if noone reads
+	c := &call{ch: make(chan invocation, 1)}
+
+	if i.inflight != nil {
+		i.inflight.Add(1)
+	}
+
+	go func() {
+		if i.inflight != nil {
+			defer i.inflight.Done()
+		}
+
+		// recover the panics, so they don't crash the process from a
+		// goroutine which the caller can't guard
+		defer func() {
+			if r := recover(); r != nil {
+				i.finish(c, invocation{panic: r})
+			}
 		}()
+
+		// operator can cancel execution with context timeout too
+		start := i.clock.Now()
+		res, err := o.Execute(ctx)
+		duration := i.clock.Since(start)
+
+		i.finish(c, invocation{res: res, err: err, duration: duration})
 	}()
 
-	return ch
+	return c
+}
+
+// finish passes the invocation result to the caller unless the caller
+// abandoned the call, the late panics are surfaced with the callback
+func (i *deadlineInvoker) finish(c *call, inv invocation) {
+	if atomic.CompareAndSwapInt32(&c.state, callRunning, callFinished) {
+		// even if noone reads, it is non-blocking with the buffered channel
+		c.ch <- inv
+		return
+	}
+
+	if inv.panic == nil {
+		return
+	}
+
+	if i.latePanicCallback != nil {
+		i.latePanicCallback(inv.panic)
+		return
+	}
+	log.Printf("shift: operator panicked after its invocation is done: %v", inv.panic)
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/clock/clocktest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,8 +15,9 @@ func TestDeadlineInvoker_Invoke(t *testing.T) {
 	t.Run("with timeout", func(t *testing.T) {
 		var called bool
 		invoker := &deadlineInvoker{
+			clock:           clock.NewSystemClock(),
 			timeout:         time.Millisecond,
-			timeoutCallback: func() { called = true },
+			timeoutCallback: func(context.Context) { called = true },
 		}
 
 		var fn Operate = func(context.Context) (interface{}, error) {
@@ -29,11 +32,138 @@ func TestDeadlineInvoker_Invoke(t *testing.T) {
 		assert.Equal(t, true, called)
 	})
 
+	t.Run("with slow call", func(t *testing.T) {
+		var slow bool
+		invoker := &deadlineInvoker{
+			clock:           clock.NewSystemClock(),
+			timeout:         time.Second,
+			timeoutCallback: func(context.Context) {},
+			slowThreshold:   time.Millisecond,
+			slowCallback:    func(context.Context) { slow = true },
+		}
+
+		t.Run("on failure", func(t *testing.T) {
+			var fn Operate = func(context.Context) (interface{}, error) {
+				time.Sleep(2 * time.Millisecond)
+				return nil, errors.New("operation error")
+			}
+			_, err := invoker.invoke(context.Background(), fn)
+
+			assert.Error(t, err)
+			assert.Equal(t, false, slow)
+		})
+
+		t.Run("on fast success", func(t *testing.T) {
+			var fn Operate = func(context.Context) (interface{}, error) {
+				return "test", nil
+			}
+			_, err := invoker.invoke(context.Background(), fn)
+
+			assert.NoError(t, err)
+			assert.Equal(t, false, slow)
+		})
+
+		t.Run("on slow success", func(t *testing.T) {
+			var fn Operate = func(context.Context) (interface{}, error) {
+				time.Sleep(2 * time.Millisecond)
+				return "test", nil
+			}
+			res, err := invoker.invoke(context.Background(), fn)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "test", res)
+			assert.Equal(t, true, slow)
+		})
+	})
+
+	t.Run("with cancellation", func(t *testing.T) {
+		var called bool
+		invoker := &deadlineInvoker{
+			clock:           clock.NewSystemClock(),
+			timeout:         time.Second,
+			timeoutCallback: func(context.Context) { called = true },
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var fn Operate = func(context.Context) (interface{}, error) {
+			cancel()
+			time.Sleep(2 * time.Millisecond)
+			return nil, nil
+		}
+		res, err := invoker.invoke(ctx, fn)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvocationTimeoutError{}, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Nil(t, res)
+		assert.Equal(t, false, called)
+	})
+
+	t.Run("with failure on the done context", func(t *testing.T) {
+		var called bool
+		invoker := &deadlineInvoker{
+			clock:           clocktest.NewClock(time.Now()),
+			timeout:         time.Second,
+			timeoutCallback: func(context.Context) { called = true },
+		}
+
+		var fn Operate = func(ctx context.Context) (interface{}, error) {
+			// the operator moves the clock beyond its deadline
+			invoker.clock.(*clocktest.Clock).Advance(time.Second)
+			return nil, ctx.Err()
+		}
+		res, err := invoker.invoke(context.Background(), fn)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvocationTimeoutError{}, err)
+		assert.Nil(t, res)
+		assert.Equal(t, true, called)
+	})
+
+	t.Run("with panic", func(t *testing.T) {
+		invoker := &deadlineInvoker{
+			clock:           clock.NewSystemClock(),
+			timeout:         time.Second,
+			timeoutCallback: func(context.Context) {},
+		}
+
+		var fn Operate = func(context.Context) (interface{}, error) {
+			panic("boom")
+		}
+		res, err := invoker.invoke(context.Background(), fn)
+
+		assert.Equal(t, &OperatorPanicError{Value: "boom"}, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("with late panic", func(t *testing.T) {
+		late := make(chan interface{}, 1)
+		invoker := &deadlineInvoker{
+			clock:             clock.NewSystemClock(),
+			timeout:           time.Millisecond,
+			timeoutCallback:   func(context.Context) {},
+			latePanicCallback: func(v interface{}) { late <- v },
+		}
+
+		release := make(chan struct{})
+		var fn Operate = func(context.Context) (interface{}, error) {
+			<-release
+			panic("boom")
+		}
+		res, err := invoker.invoke(context.Background(), fn)
+		close(release)
+
+		assert.IsType(t, &InvocationTimeoutError{}, err)
+		assert.Nil(t, res)
+		assert.Equal(t, "boom", <-late)
+	})
+
 	t.Run("without timeout", func(t *testing.T) {
 		var called bool
 		invoker := &deadlineInvoker{
+			clock:           clock.NewSystemClock(),
 			timeout:         time.Second,
-			timeoutCallback: func() { called = true },
+			timeoutCallback: func(context.Context) { called = true },
 		}
 
 		t.Run("on failure", func(t *testing.T) {
@@ -64,7 +194,7 @@ func TestDeadlineInvoker_Invoke(t *testing.T) {
 
 func TestOnOpenInvoker_Invoke(t *testing.T) {
 	var called bool
-	invoker := &onOpenInvoker{rejectCallback: func() {
+	invoker := &onOpenInvoker{rejectCallback: func(context.Context) {
 		called = true
 	}}
 
@@ -6,7 +6,9 @@ package mock
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	histogram "github.com/mustafaturan/shift/histogram"
 	reflect "reflect"
+	time "time"
 )
 
 // MockCounter is a mock of Counter interface
@@ -73,3 +75,339 @@ func (mr *MockCounterMockRecorder) Reset() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockCounter)(nil).Reset))
 }
+
+// MockStoppableCounter is a mock of StoppableCounter interface
+type MockStoppableCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoppableCounterMockRecorder
+}
+
+// MockStoppableCounterMockRecorder is the mock recorder for MockStoppableCounter
+type MockStoppableCounterMockRecorder struct {
+	mock *MockStoppableCounter
+}
+
+// NewMockStoppableCounter creates a new mock instance
+func NewMockStoppableCounter(ctrl *gomock.Controller) *MockStoppableCounter {
+	mock := &MockStoppableCounter{ctrl: ctrl}
+	mock.recorder = &MockStoppableCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStoppableCounter) EXPECT() *MockStoppableCounterMockRecorder {
+	return m.recorder
+}
+
+// Increment mocks base method
+func (m *MockStoppableCounter) Increment(metric string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Increment", metric)
+}
+
+// Increment indicates an expected call of Increment
+func (mr *MockStoppableCounterMockRecorder) Increment(metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockStoppableCounter)(nil).Increment), metric)
+}
+
+// Stats mocks base method
+func (m *MockStoppableCounter) Stats(metrics ...string) map[string]uint32 {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range metrics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
+	ret0, _ := ret[0].(map[string]uint32)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockStoppableCounterMockRecorder) Stats(metrics ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStoppableCounter)(nil).Stats), metrics...)
+}
+
+// Reset mocks base method
+func (m *MockStoppableCounter) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockStoppableCounterMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockStoppableCounter)(nil).Reset))
+}
+
+// Stop mocks base method
+func (m *MockStoppableCounter) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop
+func (mr *MockStoppableCounterMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockStoppableCounter)(nil).Stop))
+}
+
+// MockLatencyCounter is a mock of LatencyCounter interface
+type MockLatencyCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLatencyCounterMockRecorder
+}
+
+// MockLatencyCounterMockRecorder is the mock recorder for MockLatencyCounter
+type MockLatencyCounterMockRecorder struct {
+	mock *MockLatencyCounter
+}
+
+// NewMockLatencyCounter creates a new mock instance
+func NewMockLatencyCounter(ctrl *gomock.Controller) *MockLatencyCounter {
+	mock := &MockLatencyCounter{ctrl: ctrl}
+	mock.recorder = &MockLatencyCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLatencyCounter) EXPECT() *MockLatencyCounterMockRecorder {
+	return m.recorder
+}
+
+// Increment mocks base method
+func (m *MockLatencyCounter) Increment(metric string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Increment", metric)
+}
+
+// Increment indicates an expected call of Increment
+func (mr *MockLatencyCounterMockRecorder) Increment(metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockLatencyCounter)(nil).Increment), metric)
+}
+
+// Stats mocks base method
+func (m *MockLatencyCounter) Stats(metrics ...string) map[string]uint32 {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range metrics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
+	ret0, _ := ret[0].(map[string]uint32)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockLatencyCounterMockRecorder) Stats(metrics ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockLatencyCounter)(nil).Stats), metrics...)
+}
+
+// Reset mocks base method
+func (m *MockLatencyCounter) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockLatencyCounterMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLatencyCounter)(nil).Reset))
+}
+
+// Record mocks base method
+func (m *MockLatencyCounter) Record(latency time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", latency)
+}
+
+// Record indicates an expected call of Record
+func (mr *MockLatencyCounterMockRecorder) Record(latency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockLatencyCounter)(nil).Record), latency)
+}
+
+// Latencies mocks base method
+func (m *MockLatencyCounter) Latencies() histogram.Summary {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Latencies")
+	ret0, _ := ret[0].(histogram.Summary)
+	return ret0
+}
+
+// Latencies indicates an expected call of Latencies
+func (mr *MockLatencyCounterMockRecorder) Latencies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Latencies", reflect.TypeOf((*MockLatencyCounter)(nil).Latencies))
+}
+
+// MockWindowCounter is a mock of WindowCounter interface
+type MockWindowCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWindowCounterMockRecorder
+}
+
+// MockWindowCounterMockRecorder is the mock recorder for MockWindowCounter
+type MockWindowCounterMockRecorder struct {
+	mock *MockWindowCounter
+}
+
+// NewMockWindowCounter creates a new mock instance
+func NewMockWindowCounter(ctrl *gomock.Controller) *MockWindowCounter {
+	mock := &MockWindowCounter{ctrl: ctrl}
+	mock.recorder = &MockWindowCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWindowCounter) EXPECT() *MockWindowCounterMockRecorder {
+	return m.recorder
+}
+
+// Increment mocks base method
+func (m *MockWindowCounter) Increment(metric string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Increment", metric)
+}
+
+// Increment indicates an expected call of Increment
+func (mr *MockWindowCounterMockRecorder) Increment(metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockWindowCounter)(nil).Increment), metric)
+}
+
+// Stats mocks base method
+func (m *MockWindowCounter) Stats(metrics ...string) map[string]uint32 {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range metrics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
+	ret0, _ := ret[0].(map[string]uint32)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockWindowCounterMockRecorder) Stats(metrics ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockWindowCounter)(nil).Stats), metrics...)
+}
+
+// Reset mocks base method
+func (m *MockWindowCounter) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockWindowCounterMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockWindowCounter)(nil).Reset))
+}
+
+// Window mocks base method
+func (m *MockWindowCounter) Window() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Window")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Window indicates an expected call of Window
+func (mr *MockWindowCounterMockRecorder) Window() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Window", reflect.TypeOf((*MockWindowCounter)(nil).Window))
+}
+
+// MockInvocationCounter is a mock of InvocationCounter interface
+type MockInvocationCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvocationCounterMockRecorder
+}
+
+// MockInvocationCounterMockRecorder is the mock recorder for MockInvocationCounter
+type MockInvocationCounterMockRecorder struct {
+	mock *MockInvocationCounter
+}
+
+// NewMockInvocationCounter creates a new mock instance
+func NewMockInvocationCounter(ctrl *gomock.Controller) *MockInvocationCounter {
+	mock := &MockInvocationCounter{ctrl: ctrl}
+	mock.recorder = &MockInvocationCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInvocationCounter) EXPECT() *MockInvocationCounterMockRecorder {
+	return m.recorder
+}
+
+// Increment mocks base method
+func (m *MockInvocationCounter) Increment(metric string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Increment", metric)
+}
+
+// Increment indicates an expected call of Increment
+func (mr *MockInvocationCounterMockRecorder) Increment(metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockInvocationCounter)(nil).Increment), metric)
+}
+
+// Stats mocks base method
+func (m *MockInvocationCounter) Stats(metrics ...string) map[string]uint32 {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range metrics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
+	ret0, _ := ret[0].(map[string]uint32)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockInvocationCounterMockRecorder) Stats(metrics ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockInvocationCounter)(nil).Stats), metrics...)
+}
+
+// Reset mocks base method
+func (m *MockInvocationCounter) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockInvocationCounterMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockInvocationCounter)(nil).Reset))
+}
+
+// CountInvocation mocks base method
+func (m *MockInvocationCounter) CountInvocation(metrics []string, latency time.Duration, timed bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CountInvocation", metrics, latency, timed)
+}
+
+// CountInvocation indicates an expected call of CountInvocation
+func (mr *MockInvocationCounterMockRecorder) CountInvocation(metrics, latency, timed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountInvocation", reflect.TypeOf((*MockInvocationCounter)(nil).CountInvocation), metrics, latency, timed)
+}
+
+// CountRejection mocks base method
+func (m *MockInvocationCounter) CountRejection(metrics []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CountRejection", metrics)
+}
+
+// CountRejection indicates an expected call of CountRejection
+func (mr *MockInvocationCounterMockRecorder) CountRejection(metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRejection", reflect.TypeOf((*MockInvocationCounter)(nil).CountRejection), metrics)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRestrictor is a mock of Restrictor interface
@@ -59,3 +60,189 @@ func (mr *MockRestrictorMockRecorder) Defer() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockRestrictor)(nil).Defer))
 }
+
+// MockReportingRestrictor is a mock of ReportingRestrictor interface
+type MockReportingRestrictor struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportingRestrictorMockRecorder
+}
+
+// MockReportingRestrictorMockRecorder is the mock recorder for MockReportingRestrictor
+type MockReportingRestrictorMockRecorder struct {
+	mock *MockReportingRestrictor
+}
+
+// NewMockReportingRestrictor creates a new mock instance
+func NewMockReportingRestrictor(ctrl *gomock.Controller) *MockReportingRestrictor {
+	mock := &MockReportingRestrictor{ctrl: ctrl}
+	mock.recorder = &MockReportingRestrictorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReportingRestrictor) EXPECT() *MockReportingRestrictorMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method
+func (m *MockReportingRestrictor) Check(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check
+func (mr *MockReportingRestrictorMockRecorder) Check(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockReportingRestrictor)(nil).Check), arg0)
+}
+
+// Defer mocks base method
+func (m *MockReportingRestrictor) Defer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Defer")
+}
+
+// Defer indicates an expected call of Defer
+func (mr *MockReportingRestrictorMockRecorder) Defer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockReportingRestrictor)(nil).Defer))
+}
+
+// Report mocks base method
+func (m *MockReportingRestrictor) Report(latency time.Duration, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Report", latency, err)
+}
+
+// Report indicates an expected call of Report
+func (mr *MockReportingRestrictorMockRecorder) Report(latency, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockReportingRestrictor)(nil).Report), latency, err)
+}
+
+// MockPermitRestrictor is a mock of PermitRestrictor interface
+type MockPermitRestrictor struct {
+	ctrl     *gomock.Controller
+	recorder *MockPermitRestrictorMockRecorder
+}
+
+// MockPermitRestrictorMockRecorder is the mock recorder for MockPermitRestrictor
+type MockPermitRestrictorMockRecorder struct {
+	mock *MockPermitRestrictor
+}
+
+// NewMockPermitRestrictor creates a new mock instance
+func NewMockPermitRestrictor(ctrl *gomock.Controller) *MockPermitRestrictor {
+	mock := &MockPermitRestrictor{ctrl: ctrl}
+	mock.recorder = &MockPermitRestrictorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPermitRestrictor) EXPECT() *MockPermitRestrictorMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method
+func (m *MockPermitRestrictor) Check(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check
+func (mr *MockPermitRestrictorMockRecorder) Check(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockPermitRestrictor)(nil).Check), arg0)
+}
+
+// Defer mocks base method
+func (m *MockPermitRestrictor) Defer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Defer")
+}
+
+// Defer indicates an expected call of Defer
+func (mr *MockPermitRestrictorMockRecorder) Defer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockPermitRestrictor)(nil).Defer))
+}
+
+// Release mocks base method
+func (m *MockPermitRestrictor) Release() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Release")
+}
+
+// Release indicates an expected call of Release
+func (mr *MockPermitRestrictorMockRecorder) Release() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockPermitRestrictor)(nil).Release))
+}
+
+// MockStatsRestrictor is a mock of StatsRestrictor interface
+type MockStatsRestrictor struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRestrictorMockRecorder
+}
+
+// MockStatsRestrictorMockRecorder is the mock recorder for MockStatsRestrictor
+type MockStatsRestrictorMockRecorder struct {
+	mock *MockStatsRestrictor
+}
+
+// NewMockStatsRestrictor creates a new mock instance
+func NewMockStatsRestrictor(ctrl *gomock.Controller) *MockStatsRestrictor {
+	mock := &MockStatsRestrictor{ctrl: ctrl}
+	mock.recorder = &MockStatsRestrictorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStatsRestrictor) EXPECT() *MockStatsRestrictorMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method
+func (m *MockStatsRestrictor) Check(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check
+func (mr *MockStatsRestrictorMockRecorder) Check(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockStatsRestrictor)(nil).Check), arg0)
+}
+
+// Defer mocks base method
+func (m *MockStatsRestrictor) Defer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Defer")
+}
+
+// Defer indicates an expected call of Defer
+func (mr *MockStatsRestrictorMockRecorder) Defer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockStatsRestrictor)(nil).Defer))
+}
+
+// BindStats mocks base method
+func (m *MockStatsRestrictor) BindStats(stats func() (uint32, uint32)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BindStats", stats)
+}
+
+// BindStats indicates an expected call of BindStats
+func (mr *MockStatsRestrictorMockRecorder) BindStats(stats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindStats", reflect.TypeOf((*MockStatsRestrictor)(nil).BindStats), stats)
+}
@@ -58,3 +58,66 @@ func (mr *MockTimerMockRecorder) Reset() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockTimer)(nil).Reset))
 }
+
+// MockBoundedTimer is a mock of BoundedTimer interface
+type MockBoundedTimer struct {
+	ctrl     *gomock.Controller
+	recorder *MockBoundedTimerMockRecorder
+}
+
+// MockBoundedTimerMockRecorder is the mock recorder for MockBoundedTimer
+type MockBoundedTimerMockRecorder struct {
+	mock *MockBoundedTimer
+}
+
+// NewMockBoundedTimer creates a new mock instance
+func NewMockBoundedTimer(ctrl *gomock.Controller) *MockBoundedTimer {
+	mock := &MockBoundedTimer{ctrl: ctrl}
+	mock.recorder = &MockBoundedTimerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBoundedTimer) EXPECT() *MockBoundedTimerMockRecorder {
+	return m.recorder
+}
+
+// Next mocks base method
+func (m *MockBoundedTimer) Next(arg0 error) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next", arg0)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Next indicates an expected call of Next
+func (mr *MockBoundedTimerMockRecorder) Next(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockBoundedTimer)(nil).Next), arg0)
+}
+
+// Reset mocks base method
+func (m *MockBoundedTimer) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockBoundedTimerMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockBoundedTimer)(nil).Reset))
+}
+
+// Min mocks base method
+func (m *MockBoundedTimer) Min() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Min")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Min indicates an expected call of Min
+func (mr *MockBoundedTimerMockRecorder) Min() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Min", reflect.TypeOf((*MockBoundedTimer)(nil).Min))
+}
@@ -0,0 +1,343 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+// TripPolicy decides the target state of the circuit breaker after the
+// invocations on 'close' and 'half-open' states. The policies return the given
+// state to keep the circuit breaker as is. The rejections are evaluated as
+// failures, the ignored and custom categorized invocations are not evaluated.
+type TripPolicy interface {
+	Evaluate(state State, stats Stats, outcome Outcome) State
+}
+
+// ReasoningTripPolicy is an optional interface for trip policies which explain
+// their decisions. The reason of tripping to 'open' state is passed to the
+// reset timer and the state change handlers, the circuit breaker falls back to
+// FailureThresholdReachedError on failures and PolicyTrippedError otherwise
+// when the reason is nil.
+type ReasoningTripPolicy interface {
+	TripPolicy
+	Decide(state State, stats Stats, outcome Outcome) (State, error)
+}
+
+// TripPolicyFunc is a function to decide the target state of the circuit
+// breaker
+type TripPolicyFunc func(state State, stats Stats, outcome Outcome) State
+
+// Evaluate implements TripPolicy for TripPolicyFunc func
+func (fn TripPolicyFunc) Evaluate(state State, stats Stats, outcome Outcome) State {
+	return fn(state, stats, outcome)
+}
+
+// RatioOpenPolicy trips to 'open' state on failures when the success ratio
+// drops under the min success ratio at min number of requests
+type RatioOpenPolicy struct {
+	state           State
+	minSuccessRatio float32
+	minRequests     uint32
+}
+
+// NewRatioOpenPolicy inits a new RatioOpenPolicy for the given state
+func NewRatioOpenPolicy(state State, minSuccessRatio float32, minRequests uint32) (*RatioOpenPolicy, error) {
+	if !state.isClose() && !state.isHalfOpen() {
+		return nil, &InvalidOptionError{
+			Name:    "state for failure criteria",
+			Message: "can only be applied to 'close' and 'half open' states",
+		}
+	}
+
+	if minSuccessRatio <= 0.0 || minSuccessRatio > 100.0 {
+		return nil, &InvalidOptionError{
+			Name:    "min success ratio to trip to 'open' state",
+			Message: "can be greater than 0.0 and less than equal to 100.0",
+		}
+	}
+
+	if minRequests < 1 {
+		return nil, &InvalidOptionError{
+			Name:    "min requests to check success ratio",
+			Message: "must be positive int",
+		}
+	}
+
+	return &RatioOpenPolicy{
+		state:           state,
+		minSuccessRatio: minSuccessRatio,
+		minRequests:     minRequests,
+	}, nil
+}
+
+// Evaluate implements TripPolicy for RatioOpenPolicy
+func (p *RatioOpenPolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	if state != p.state || outcome != OutcomeFailure {
+		return state
+	}
+
+	ratio, ok := successRatio(stats, p.minRequests)
+	if ok && ratio < p.minSuccessRatio {
+		return StateOpen
+	}
+	return state
+}
+
+// RatioClosePolicy trips from 'half-open' to 'close' state on successes when
+// the success ratio reaches the min success ratio at min number of requests
+type RatioClosePolicy struct {
+	minSuccessRatio float32
+	minRequests     uint32
+}
+
+// NewRatioClosePolicy inits a new RatioClosePolicy
+func NewRatioClosePolicy(minSuccessRatio float32, minRequests uint32) (*RatioClosePolicy, error) {
+	if minSuccessRatio <= 0.0 || minSuccessRatio > 100.0 {
+		return nil, &InvalidOptionError{
+			Name:    "min success ratio to trip to 'close' state",
+			Message: "can be greater than 0.0 and less than equal to 100.0",
+		}
+	}
+
+	if minRequests < 1 {
+		return nil, &InvalidOptionError{
+			Name:    "min requests to check success ratio",
+			Message: "must be positive int",
+		}
+	}
+
+	return &RatioClosePolicy{
+		minSuccessRatio: minSuccessRatio,
+		minRequests:     minRequests,
+	}, nil
+}
+
+// Evaluate implements TripPolicy for RatioClosePolicy
+func (p *RatioClosePolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	if !state.isHalfOpen() || outcome != OutcomeSuccess {
+		return state
+	}
+
+	ratio, ok := successRatio(stats, p.minRequests)
+	if ok && ratio >= p.minSuccessRatio {
+		return StateClose
+	}
+	return state
+}
+
+// ConsecutiveOpenPolicy trips to 'open' state on the given number of failures
+// in a row
+type ConsecutiveOpenPolicy struct {
+	state    State
+	failures uint32
+}
+
+// NewConsecutiveOpenPolicy inits a new ConsecutiveOpenPolicy for the given
+// state
+func NewConsecutiveOpenPolicy(state State, failures uint32) (*ConsecutiveOpenPolicy, error) {
+	if !state.isClose() && !state.isHalfOpen() {
+		return nil, &InvalidOptionError{
+			Name:    "state for consecutive failures criteria",
+			Message: "can only be applied to 'close' and 'half open' states",
+		}
+	}
+
+	if failures < 1 {
+		return nil, &InvalidOptionError{
+			Name:    "consecutive failures to trip to 'open' state",
+			Message: "must be positive int",
+		}
+	}
+
+	return &ConsecutiveOpenPolicy{state: state, failures: failures}, nil
+}
+
+// Evaluate implements TripPolicy for ConsecutiveOpenPolicy
+func (p *ConsecutiveOpenPolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	if state != p.state || outcome != OutcomeFailure {
+		return state
+	}
+
+	if stats.ConsecutiveFailures >= p.failures {
+		return StateOpen
+	}
+	return state
+}
+
+// ConsecutiveClosePolicy trips from 'half-open' to 'close' state on the given
+// number of successes in a row
+type ConsecutiveClosePolicy struct {
+	successes uint32
+}
+
+// NewConsecutiveClosePolicy inits a new ConsecutiveClosePolicy
+func NewConsecutiveClosePolicy(successes uint32) (*ConsecutiveClosePolicy, error) {
+	if successes < 1 {
+		return nil, &InvalidOptionError{
+			Name:    "consecutive successes to trip to 'close' state",
+			Message: "must be positive int",
+		}
+	}
+
+	return &ConsecutiveClosePolicy{successes: successes}, nil
+}
+
+// Evaluate implements TripPolicy for ConsecutiveClosePolicy
+func (p *ConsecutiveClosePolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	if !state.isHalfOpen() || outcome != OutcomeSuccess {
+		return state
+	}
+
+	if stats.ConsecutiveSuccesses >= p.successes {
+		return StateClose
+	}
+	return state
+}
+
+// SlowCallOpenPolicy trips to 'open' state on successes when the slow call
+// ratio exceeds the max slow call ratio at min number of requests
+type SlowCallOpenPolicy struct {
+	state        State
+	maxSlowRatio float32
+	minRequests  uint32
+}
+
+// NewSlowCallOpenPolicy inits a new SlowCallOpenPolicy for the given state
+func NewSlowCallOpenPolicy(state State, maxSlowRatio float32, minRequests uint32) (*SlowCallOpenPolicy, error) {
+	if !state.isClose() && !state.isHalfOpen() {
+		return nil, &InvalidOptionError{
+			Name:    "state for slow call criteria",
+			Message: "can only be applied to 'close' and 'half open' states",
+		}
+	}
+
+	if maxSlowRatio < 0.0 || maxSlowRatio >= 100.0 {
+		return nil, &InvalidOptionError{
+			Name:    "max slow call ratio to trip to 'open' state",
+			Message: "can be greater than or equal to 0.0 and less than 100.0",
+		}
+	}
+
+	if minRequests < 1 {
+		return nil, &InvalidOptionError{
+			Name:    "min requests to check slow call ratio",
+			Message: "must be positive int",
+		}
+	}
+
+	return &SlowCallOpenPolicy{
+		state:        state,
+		maxSlowRatio: maxSlowRatio,
+		minRequests:  minRequests,
+	}, nil
+}
+
+// Evaluate implements TripPolicy for SlowCallOpenPolicy
+func (p *SlowCallOpenPolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	to, _ := p.Decide(state, stats, outcome)
+	return to
+}
+
+// Decide implements ReasoningTripPolicy for SlowCallOpenPolicy, the reason is
+// a SlowCallThresholdReachedError
+func (p *SlowCallOpenPolicy) Decide(state State, stats Stats, outcome Outcome) (State, error) {
+	if state != p.state || outcome != OutcomeSuccess {
+		return state, nil
+	}
+
+	ratio, ok := slowCallRatio(stats, p.minRequests)
+	if ok && ratio > p.maxSlowRatio {
+		return StateOpen, &SlowCallThresholdReachedError{Ratio: ratio}
+	}
+	return state, nil
+}
+
+// anyPolicy returns the first target state other than the given state from the
+// policies in order
+type anyPolicy []TripPolicy
+
+// AnyPolicy builds a policy which returns the first target state other than
+// the given state from the policies in order, the reason is the reason of the
+// matching policy
+func AnyPolicy(policies ...TripPolicy) TripPolicy {
+	return anyPolicy(policies)
+}
+
+// Evaluate implements TripPolicy for anyPolicy
+func (p anyPolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	to, _ := p.Decide(state, stats, outcome)
+	return to
+}
+
+// Decide implements ReasoningTripPolicy for anyPolicy
+func (p anyPolicy) Decide(state State, stats Stats, outcome Outcome) (State, error) {
+	for _, policy := range p {
+		if to, reason := decide(policy, state, stats, outcome); to != state {
+			return to, reason
+		}
+	}
+	return state, nil
+}
+
+// allPolicy returns the target state only when all the policies agree on the
+// same target state other than the given state
+type allPolicy []TripPolicy
+
+// AllPolicy builds a policy which returns the target state only when all the
+// policies agree on the same target state other than the given state, the
+// reason is the first reason of the policies
+func AllPolicy(policies ...TripPolicy) TripPolicy {
+	return allPolicy(policies)
+}
+
+// Evaluate implements TripPolicy for allPolicy
+func (p allPolicy) Evaluate(state State, stats Stats, outcome Outcome) State {
+	to, _ := p.Decide(state, stats, outcome)
+	return to
+}
+
+// Decide implements ReasoningTripPolicy for allPolicy
+func (p allPolicy) Decide(state State, stats Stats, outcome Outcome) (State, error) {
+	if len(p) == 0 {
+		return state, nil
+	}
+
+	to, reason := decide(p[0], state, stats, outcome)
+	for _, policy := range p[1:] {
+		next, r := decide(policy, state, stats, outcome)
+		if next != to {
+			return state, nil
+		}
+		if reason == nil {
+			reason = r
+		}
+	}
+	return to, reason
+}
+
+// decide returns the target state and the reason from the given policy
+func decide(p TripPolicy, state State, stats Stats, outcome Outcome) (State, error) {
+	if rp, ok := p.(ReasoningTripPolicy); ok {
+		return rp.Decide(state, stats, outcome)
+	}
+	return p.Evaluate(state, stats, outcome), nil
+}
+
+// successRatio returns the success ratio if the stats have min number of
+// requests
+func successRatio(stats Stats, minRequests uint32) (float32, bool) {
+	requests := stats.SuccessCount + stats.FailureCount - stats.RejectCount
+	if requests < minRequests {
+		return 0, false
+	}
+	return float32(stats.SuccessCount) / float32(requests) * 100, true
+}
+
+// slowCallRatio returns the slow call ratio if the stats have min number of
+// requests
+func slowCallRatio(stats Stats, minRequests uint32) (float32, bool) {
+	requests := stats.SuccessCount + stats.FailureCount - stats.RejectCount
+	if requests < minRequests {
+		return 0, false
+	}
+	return float32(stats.SlowCount) / float32(requests) * 100, true
+}
@@ -0,0 +1,257 @@
+package shift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTripPolicyFunc_Evaluate(t *testing.T) {
+	var fn TripPolicyFunc = func(State, Stats, Outcome) State {
+		return StateOpen
+	}
+	assert.Equal(t, StateOpen, fn.Evaluate(StateClose, Stats{}, OutcomeFailure))
+}
+
+func TestNewRatioOpenPolicy(t *testing.T) {
+	t.Run("with invalid state", func(t *testing.T) {
+		p, err := NewRatioOpenPolicy(StateOpen, 90.0, 10)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid ratio", func(t *testing.T) {
+		p, err := NewRatioOpenPolicy(StateClose, 0.0, 10)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid min requests", func(t *testing.T) {
+		p, err := NewRatioOpenPolicy(StateClose, 90.0, 0)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+}
+
+func TestRatioOpenPolicy_Evaluate(t *testing.T) {
+	p, err := NewRatioOpenPolicy(StateClose, 90.0, 10)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		state    State
+		stats    Stats
+		outcome  Outcome
+		expected State
+	}{
+		{"on another state", StateHalfOpen, Stats{FailureCount: 10}, OutcomeFailure, StateHalfOpen},
+		{"on success", StateClose, Stats{FailureCount: 10}, OutcomeSuccess, StateClose},
+		{"under min requests", StateClose, Stats{FailureCount: 10, RejectCount: 1}, OutcomeFailure, StateClose},
+		{"above min success ratio", StateClose, Stats{SuccessCount: 9, FailureCount: 1}, OutcomeFailure, StateClose},
+		{"under min success ratio", StateClose, Stats{SuccessCount: 8, FailureCount: 2}, OutcomeFailure, StateOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, p.Evaluate(test.state, test.stats, test.outcome))
+		})
+	}
+}
+
+func TestNewRatioClosePolicy(t *testing.T) {
+	t.Run("with invalid ratio", func(t *testing.T) {
+		p, err := NewRatioClosePolicy(100.1, 10)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid min requests", func(t *testing.T) {
+		p, err := NewRatioClosePolicy(90.0, 0)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+}
+
+func TestRatioClosePolicy_Evaluate(t *testing.T) {
+	p, err := NewRatioClosePolicy(90.0, 10)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		state    State
+		stats    Stats
+		outcome  Outcome
+		expected State
+	}{
+		{"on another state", StateClose, Stats{SuccessCount: 10}, OutcomeSuccess, StateClose},
+		{"on failure", StateHalfOpen, Stats{SuccessCount: 10}, OutcomeFailure, StateHalfOpen},
+		{"under min requests", StateHalfOpen, Stats{SuccessCount: 9}, OutcomeSuccess, StateHalfOpen},
+		{"under min success ratio", StateHalfOpen, Stats{SuccessCount: 8, FailureCount: 2}, OutcomeSuccess, StateHalfOpen},
+		{"reaches min success ratio", StateHalfOpen, Stats{SuccessCount: 9, FailureCount: 1}, OutcomeSuccess, StateClose},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, p.Evaluate(test.state, test.stats, test.outcome))
+		})
+	}
+}
+
+func TestNewConsecutiveOpenPolicy(t *testing.T) {
+	t.Run("with invalid state", func(t *testing.T) {
+		p, err := NewConsecutiveOpenPolicy(StateOpen, 3)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid failures", func(t *testing.T) {
+		p, err := NewConsecutiveOpenPolicy(StateClose, 0)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+}
+
+func TestConsecutiveOpenPolicy_Evaluate(t *testing.T) {
+	p, err := NewConsecutiveOpenPolicy(StateHalfOpen, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, StateClose, p.Evaluate(StateClose, Stats{ConsecutiveFailures: 3}, OutcomeFailure))
+	assert.Equal(t, StateHalfOpen, p.Evaluate(StateHalfOpen, Stats{ConsecutiveFailures: 3}, OutcomeSuccess))
+	assert.Equal(t, StateHalfOpen, p.Evaluate(StateHalfOpen, Stats{ConsecutiveFailures: 2}, OutcomeFailure))
+	assert.Equal(t, StateOpen, p.Evaluate(StateHalfOpen, Stats{ConsecutiveFailures: 3}, OutcomeFailure))
+}
+
+func TestNewConsecutiveClosePolicy(t *testing.T) {
+	p, err := NewConsecutiveClosePolicy(0)
+	assert.Nil(t, p)
+	assert.IsType(t, &InvalidOptionError{}, err)
+}
+
+func TestConsecutiveClosePolicy_Evaluate(t *testing.T) {
+	p, err := NewConsecutiveClosePolicy(3)
+	require.NoError(t, err)
+
+	assert.Equal(t, StateClose, p.Evaluate(StateClose, Stats{ConsecutiveSuccesses: 3}, OutcomeSuccess))
+	assert.Equal(t, StateHalfOpen, p.Evaluate(StateHalfOpen, Stats{ConsecutiveSuccesses: 3}, OutcomeFailure))
+	assert.Equal(t, StateHalfOpen, p.Evaluate(StateHalfOpen, Stats{ConsecutiveSuccesses: 2}, OutcomeSuccess))
+	assert.Equal(t, StateClose, p.Evaluate(StateHalfOpen, Stats{ConsecutiveSuccesses: 3}, OutcomeSuccess))
+}
+
+func TestNewSlowCallOpenPolicy(t *testing.T) {
+	t.Run("with invalid state", func(t *testing.T) {
+		p, err := NewSlowCallOpenPolicy(StateOpen, 50.0, 10)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid ratio", func(t *testing.T) {
+		p, err := NewSlowCallOpenPolicy(StateClose, 100.0, 10)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid min requests", func(t *testing.T) {
+		p, err := NewSlowCallOpenPolicy(StateClose, 50.0, 0)
+		assert.Nil(t, p)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+}
+
+func TestSlowCallOpenPolicy_Evaluate(t *testing.T) {
+	p, err := NewSlowCallOpenPolicy(StateClose, 50.0, 10)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		state    State
+		stats    Stats
+		outcome  Outcome
+		expected State
+	}{
+		{"on another state", StateHalfOpen, Stats{SuccessCount: 10, SlowCount: 10}, OutcomeSuccess, StateHalfOpen},
+		{"on failure", StateClose, Stats{SuccessCount: 10, SlowCount: 10}, OutcomeFailure, StateClose},
+		{"under min requests", StateClose, Stats{SuccessCount: 9, SlowCount: 9}, OutcomeSuccess, StateClose},
+		{"under max slow ratio", StateClose, Stats{SuccessCount: 10, SlowCount: 5}, OutcomeSuccess, StateClose},
+		{"above max slow ratio", StateClose, Stats{SuccessCount: 10, SlowCount: 6}, OutcomeSuccess, StateOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, p.Evaluate(test.state, test.stats, test.outcome))
+		})
+	}
+}
+
+func TestSlowCallOpenPolicy_Decide(t *testing.T) {
+	p, err := NewSlowCallOpenPolicy(StateClose, 50.0, 10)
+	require.NoError(t, err)
+
+	to, reason := p.Decide(StateClose, Stats{SuccessCount: 10, SlowCount: 5}, OutcomeSuccess)
+	assert.Equal(t, StateClose, to)
+	assert.Nil(t, reason)
+
+	to, reason = p.Decide(StateClose, Stats{SuccessCount: 12, SlowCount: 9}, OutcomeSuccess)
+	assert.Equal(t, StateOpen, to)
+	assert.Equal(t, &SlowCallThresholdReachedError{Ratio: 75.0}, reason)
+}
+
+func TestAnyPolicy(t *testing.T) {
+	var keep TripPolicyFunc = func(state State, _ Stats, _ Outcome) State {
+		return state
+	}
+	var open TripPolicyFunc = func(State, Stats, Outcome) State {
+		return StateOpen
+	}
+	var close TripPolicyFunc = func(State, Stats, Outcome) State {
+		return StateClose
+	}
+
+	assert.Equal(t, StateHalfOpen, AnyPolicy().Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateHalfOpen, AnyPolicy(keep).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateOpen, AnyPolicy(keep, open, close).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateClose, AnyPolicy(close, open).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+
+	t.Run("decides with the reason of the matching policy", func(t *testing.T) {
+		slow, _ := NewSlowCallOpenPolicy(StateClose, 50.0, 1)
+		stats := Stats{SuccessCount: 1, SlowCount: 1}
+
+		to, reason := AnyPolicy(keep, slow, open).(ReasoningTripPolicy).Decide(StateClose, stats, OutcomeSuccess)
+		assert.Equal(t, StateOpen, to)
+		assert.IsType(t, &SlowCallThresholdReachedError{}, reason)
+
+		to, reason = AnyPolicy(open, slow).(ReasoningTripPolicy).Decide(StateClose, stats, OutcomeSuccess)
+		assert.Equal(t, StateOpen, to)
+		assert.Nil(t, reason)
+	})
+}
+
+func TestAllPolicy(t *testing.T) {
+	var keep TripPolicyFunc = func(state State, _ Stats, _ Outcome) State {
+		return state
+	}
+	var open TripPolicyFunc = func(State, Stats, Outcome) State {
+		return StateOpen
+	}
+	var close TripPolicyFunc = func(State, Stats, Outcome) State {
+		return StateClose
+	}
+
+	assert.Equal(t, StateHalfOpen, AllPolicy().Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateHalfOpen, AllPolicy(open, keep).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateHalfOpen, AllPolicy(open, close).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+	assert.Equal(t, StateOpen, AllPolicy(open, open).Evaluate(StateHalfOpen, Stats{}, OutcomeFailure))
+
+	t.Run("decides with the first reason of the policies", func(t *testing.T) {
+		slow, _ := NewSlowCallOpenPolicy(StateClose, 50.0, 1)
+		stats := Stats{SuccessCount: 1, SlowCount: 1}
+
+		to, reason := AllPolicy(open, slow).(ReasoningTripPolicy).Decide(StateClose, stats, OutcomeSuccess)
+		assert.Equal(t, StateOpen, to)
+		assert.IsType(t, &SlowCallThresholdReachedError{}, reason)
+
+		to, reason = AllPolicy(keep, slow).(ReasoningTripPolicy).Decide(StateClose, stats, OutcomeSuccess)
+		assert.Equal(t, StateClose, to)
+		assert.Nil(t, reason)
+	})
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "sync/atomic"
+
+// probes limits the number of in-flight trial invocations on half-open state,
+// zero max means unlimited
+type probes struct {
+	max, inflight int64
+}
+
+// acquire admits a probe if the number of in-flight probes is under the max
+func (p *probes) acquire() bool {
+	if p.max == 0 {
+		return true
+	}
+
+	if atomic.AddInt64(&p.inflight, 1) > p.max {
+		atomic.AddInt64(&p.inflight, -1)
+		return false
+	}
+	return true
+}
+
+// release frees an admitted probe
+func (p *probes) release() {
+	if p.max == 0 {
+		return
+	}
+	atomic.AddInt64(&p.inflight, -1)
+}
@@ -0,0 +1,30 @@
+package shift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbes(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		var p probes
+		for i := 0; i < 10; i++ {
+			assert.True(t, p.acquire())
+		}
+		p.release()
+		assert.Equal(t, int64(0), p.inflight)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		p := probes{max: 2}
+		assert.True(t, p.acquire())
+		assert.True(t, p.acquire())
+		assert.False(t, p.acquire())
+		assert.Equal(t, int64(2), p.inflight)
+
+		p.release()
+		assert.True(t, p.acquire())
+		assert.False(t, p.acquire())
+	})
+}
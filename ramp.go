@@ -0,0 +1,70 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// ramp admits a growing percentage of the invocations on half-open state, the
+// percentage moves to the next stage on every step duration
+type ramp struct {
+	percents []float64
+	step     time.Duration
+
+	// startedAt is the start time of the ramp in unix nanoseconds
+	startedAt int64
+
+	// random returns a pseudo-random number in [0.0,1.0)
+	random func() float64
+}
+
+// enabled checks if the ramp has stages
+func (r *ramp) enabled() bool {
+	return len(r.percents) > 0
+}
+
+// start restarts the ramp from the first stage
+func (r *ramp) start(now time.Time) {
+	atomic.StoreInt64(&r.startedAt, now.UnixNano())
+}
+
+// stage returns the index of the current stage
+func (r *ramp) stage(now time.Time) int {
+	elapsed := now.UnixNano() - atomic.LoadInt64(&r.startedAt)
+	if elapsed < 0 {
+		return 0
+	}
+
+	i := elapsed / int64(r.step)
+	if last := int64(len(r.percents) - 1); i > last {
+		return int(last)
+	}
+	return int(i)
+}
+
+// percent returns the admitted percentage of the invocations
+func (r *ramp) percent(now time.Time) float64 {
+	if !r.enabled() {
+		return 100.0
+	}
+	return r.percents[r.stage(now)]
+}
+
+// admit decides if the invocation is admitted on the current stage
+func (r *ramp) admit(now time.Time) (bool, float64) {
+	percent := r.percent(now)
+	return percent >= 100.0 || r.random()*100.0 < percent, percent
+}
+
+// completed checks if the ramp reached its last stage
+func (r *ramp) completed(now time.Time) bool {
+	return !r.enabled() || r.stage(now) == len(r.percents)-1
+}
+
+// defaultRandom is the default random source of the ramps
+var defaultRandom = rand.Float64
@@ -0,0 +1,59 @@
+package shift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRamp(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var r ramp
+		now := time.Now()
+
+		ok, percent := r.admit(now)
+		assert.True(t, ok)
+		assert.Equal(t, 100.0, percent)
+		assert.True(t, r.completed(now))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		random := 0.3
+		r := ramp{
+			percents: []float64{5, 25, 50, 100},
+			step:     time.Minute,
+			random:   func() float64 { return random },
+		}
+		now := time.Now()
+		r.start(now)
+
+		tests := []struct {
+			elapsed   time.Duration
+			stage     int
+			percent   float64
+			admitted  bool
+			completed bool
+		}{
+			{0, 0, 5, false, false},
+			{time.Minute, 1, 25, false, false},
+			{2*time.Minute + time.Second, 2, 50, true, false},
+			{3 * time.Minute, 3, 100, true, true},
+			{time.Hour, 3, 100, true, true},
+		}
+
+		for _, test := range tests {
+			at := now.Add(test.elapsed)
+			ok, percent := r.admit(at)
+			assert.Equal(t, test.stage, r.stage(at))
+			assert.Equal(t, test.percent, percent)
+			assert.Equal(t, test.admitted, ok)
+			assert.Equal(t, test.completed, r.completed(at))
+		}
+
+		// Restarts from the first stage
+		r.start(now.Add(time.Hour))
+		assert.Equal(t, 0, r.stage(now.Add(time.Hour)))
+		assert.Equal(t, 0, r.stage(now))
+	})
+}
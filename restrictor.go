@@ -4,7 +4,10 @@
 
 package shift
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Restrictor allows adding restriction to circuit breaker
 type Restrictor interface {
@@ -15,3 +18,37 @@ type Restrictor interface {
 	// Defer executes exit rules of the restrictor right after the run process
 	Defer()
 }
+
+// ReportingRestrictor is an optional interface for restrictors which adjust
+// their rules depending on the invocation outcomes
+type ReportingRestrictor interface {
+	Restrictor
+
+	// Report receives the latency and the error of the invocation right before
+	// the Defer call, it is only called for the invocations classified as
+	// success or failure
+	Report(latency time.Duration, err error)
+}
+
+// PermitRestrictor is an optional interface for restrictors which hold permits
+// only for the admitted invocations. The circuit breaker calls Release instead
+// of Defer and only for the admitted invocations, so a rejected invocation
+// can't release the permit of another invocation.
+type PermitRestrictor interface {
+	Restrictor
+
+	// Release releases the permit of the admitted invocation right after the
+	// run process
+	Release()
+}
+
+// StatsRestrictor is an optional interface for restrictors which adjust their
+// rules depending on the stats of the circuit breaker
+type StatsRestrictor interface {
+	Restrictor
+
+	// BindStats receives the func returning the successful and failed
+	// invocation counts of the circuit breaker excluding the rejections, it
+	// is called once on the circuit breaker init
+	BindStats(stats func() (successes, failures uint32))
+}
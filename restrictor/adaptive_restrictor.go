@@ -0,0 +1,56 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// AdaptiveRestrictor is a restrictor for concurrent runs with a limit learned
+// from the observed invocation latencies and failures
+type AdaptiveRestrictor struct {
+	name    string
+	current int64
+	limit   Limit
+}
+
+// NewAdaptiveRestrictor inits a new adaptive restrictor with the given limit
+// algorithm like AIMDLimit or GradientLimit
+func NewAdaptiveRestrictor(name string, limit Limit) (*AdaptiveRestrictor, error) {
+	if limit == nil {
+		return nil, &InvalidOptionError{
+			Name: "adaptive restrictor limit",
+			Type: "non-nil limit algorithm",
+		}
+	}
+	return &AdaptiveRestrictor{
+		name:  name,
+		limit: limit,
+	}, nil
+}
+
+// Check checks if possible to add new runs within the current limit
+func (r *AdaptiveRestrictor) Check(_ context.Context) (bool, error) {
+	limit := r.limit.Limit()
+	if limit < atomic.AddInt64(&r.current, 1) {
+		return false, &ThresholdError{
+			Name:      r.name,
+			Threshold: limit,
+		}
+	}
+	return true, nil
+}
+
+// Report updates the limit with the invocation outcome
+func (r *AdaptiveRestrictor) Report(latency time.Duration, err error) {
+	r.limit.Update(atomic.LoadInt64(&r.current), latency, err != nil)
+}
+
+// Defer removes 1 from current runs
+func (r *AdaptiveRestrictor) Defer() {
+	atomic.AddInt64(&r.current, -1)
+}
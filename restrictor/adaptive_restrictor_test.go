@@ -0,0 +1,86 @@
+package restrictor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdaptiveRestrictor(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		l, _ := NewAIMDLimit(2, 1, 10, 0.5, time.Second)
+		r, err := NewAdaptiveRestrictor("test", l)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+		assert.IsType(t, &AdaptiveRestrictor{}, r)
+		assert.Equal(t, "test", r.name)
+		assert.Equal(t, l, r.limit)
+		assert.Equal(t, int64(0), r.current)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		r, err := NewAdaptiveRestrictor("test", nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestAdaptiveRestrictor_Check(t *testing.T) {
+	t.Run("under limit", func(t *testing.T) {
+		l, _ := NewAIMDLimit(1, 1, 10, 0.5, time.Second)
+		r, _ := NewAdaptiveRestrictor("test", l)
+		ok, err := r.Check(context.Background())
+		assert.True(t, ok)
+		assert.NoError(t, err)
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		l, _ := NewAIMDLimit(1, 1, 10, 0.5, time.Second)
+		r, _ := NewAdaptiveRestrictor("test", l)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		ok, err := r.Check(context.Background())
+		assert.False(t, ok)
+		assert.Error(t, err)
+		assert.IsType(t, &ThresholdError{}, err)
+	})
+}
+
+func TestAdaptiveRestrictor_Report(t *testing.T) {
+	t.Run("on success", func(t *testing.T) {
+		l, _ := NewAIMDLimit(2, 1, 10, 0.5, time.Second)
+		r, _ := NewAdaptiveRestrictor("test", l)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		r.Report(time.Millisecond, nil)
+		assert.Equal(t, int64(3), l.Limit())
+	})
+
+	t.Run("on failure", func(t *testing.T) {
+		l, _ := NewAIMDLimit(4, 1, 10, 0.5, time.Second)
+		r, _ := NewAdaptiveRestrictor("test", l)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		r.Report(time.Millisecond, errors.New("failed"))
+		assert.Equal(t, int64(2), l.Limit())
+	})
+}
+
+func TestAdaptiveRestrictor_Defer(t *testing.T) {
+	l, _ := NewAIMDLimit(1, 1, 10, 0.5, time.Second)
+	r, _ := NewAdaptiveRestrictor("test", l)
+	_, err := r.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), r.current)
+	r.Defer()
+	assert.Equal(t, int64(0), r.current)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+)
+
+// BulkheadRestrictor is a restrictor for concurrent runs with a bounded FIFO
+// wait queue, the invocations wait for a permit instead of being rejected
+// immediately while the queue has room. The permits of the admitted checks are
+// released with Release, the rejected checks hold no permits.
+type BulkheadRestrictor struct {
+	mutex sync.Mutex
+
+	name           string
+	permits, queue int64
+	maxWait        time.Duration
+
+	available int64
+	waiters   *list.List
+	clock     clock.Clock
+}
+
+// NewBulkheadRestrictor inits a new bulkhead restrictor with the given number of
+// permits, wait queue capacity and max wait duration in the queue. The clock of
+// the max waits can be set with the WithClock option.
+func NewBulkheadRestrictor(name string, permits, queue int64, maxWait time.Duration, opts ...Option) (*BulkheadRestrictor, error) {
+	if permits < 1 {
+		return nil, &InvalidOptionError{
+			Name: "bulkhead permits",
+			Type: "positive integer",
+		}
+	}
+
+	if queue < 0 {
+		return nil, &InvalidOptionError{
+			Name: "bulkhead queue capacity",
+			Type: "non-negative integer",
+		}
+	}
+
+	if maxWait <= 0 {
+		return nil, &InvalidOptionError{
+			Name: "bulkhead max wait duration",
+			Type: "positive duration",
+		}
+	}
+
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BulkheadRestrictor{
+		name:      name,
+		permits:   permits,
+		queue:     queue,
+		maxWait:   maxWait,
+		available: permits,
+		waiters:   list.New(),
+		clock:     o.clock,
+	}, nil
+}
+
+// Check acquires a permit if available, otherwise waits in the queue until a
+// permit frees, the context gets cancelled or the max wait duration elapses
+func (r *BulkheadRestrictor) Check(ctx context.Context) (bool, error) {
+	r.mutex.Lock()
+
+	if r.available > 0 {
+		r.available--
+		r.mutex.Unlock()
+		return true, nil
+	}
+
+	if int64(r.waiters.Len()) >= r.queue {
+		r.mutex.Unlock()
+		return false, &QueueFullError{
+			Name:  r.name,
+			Queue: r.queue,
+		}
+	}
+
+	// Buffered to hand over the permit without blocking the releaser
+	permit := make(chan struct{}, 1)
+	waiter := r.waiters.PushBack(permit)
+	r.mutex.Unlock()
+
+	timer := r.clock.NewTimer(r.maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-permit:
+		return true, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C():
+		err = &WaitTimeoutError{
+			Name:     r.name,
+			Duration: r.maxWait,
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	select {
+	case <-permit:
+		// The permit was handed over right before giving up, pass it on
+		r.release()
+	default:
+		r.waiters.Remove(waiter)
+	}
+
+	return false, err
+}
+
+// Release releases the permit of an admitted check to the next waiter in the
+// queue, the circuit breaker calls it only for the admitted invocations
+func (r *BulkheadRestrictor) Release() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.release()
+}
+
+// Defer releases the permit like Release for the direct usages, it must only
+// be called for the admitted checks. The circuit breaker calls Release instead.
+func (r *BulkheadRestrictor) Defer() {
+	r.Release()
+}
+
+func (r *BulkheadRestrictor) release() {
+	front := r.waiters.Front()
+	if front == nil {
+		r.available++
+		return
+	}
+
+	r.waiters.Remove(front)
+	front.Value.(chan struct{}) <- struct{}{}
+}
@@ -0,0 +1,213 @@
+package restrictor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBulkheadRestrictor(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		r, err := NewBulkheadRestrictor("test", 2, 3, time.Second)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+		assert.IsType(t, &BulkheadRestrictor{}, r)
+		assert.Equal(t, "test", r.name)
+		assert.Equal(t, int64(2), r.permits)
+		assert.Equal(t, int64(2), r.available)
+		assert.Equal(t, int64(3), r.queue)
+		assert.Equal(t, time.Second, r.maxWait)
+		assert.IsType(t, &clock.SystemClock{}, r.clock)
+	})
+
+	t.Run("invalid clock", func(t *testing.T) {
+		r, err := NewBulkheadRestrictor("test", 1, 1, time.Second, WithClock(nil))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid permits", func(t *testing.T) {
+		r, err := NewBulkheadRestrictor("test", 0, 3, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid queue", func(t *testing.T) {
+		r, err := NewBulkheadRestrictor("test", 1, -1, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid max wait", func(t *testing.T) {
+		r, err := NewBulkheadRestrictor("test", 1, 1, 0)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestBulkheadRestrictor_Check(t *testing.T) {
+	t.Run("with available permits", func(t *testing.T) {
+		r, _ := NewBulkheadRestrictor("test", 1, 0, time.Second)
+		ok, err := r.Check(context.Background())
+		assert.True(t, ok)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), r.available)
+	})
+
+	t.Run("with full queue", func(t *testing.T) {
+		r, _ := NewBulkheadRestrictor("test", 1, 0, time.Second)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		ok, err := r.Check(context.Background())
+		assert.False(t, ok)
+		assert.Error(t, err)
+		assert.IsType(t, &QueueFullError{}, err)
+	})
+
+	t.Run("waits for a released permit", func(t *testing.T) {
+		r, _ := NewBulkheadRestrictor("test", 1, 1, time.Second)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		go func() {
+			waitForWaiters(r, 1)
+			r.Defer()
+		}()
+
+		ok, err := r.Check(context.Background())
+		assert.True(t, ok)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), r.available)
+	})
+
+	t.Run("waits in order", func(t *testing.T) {
+		r, _ := NewBulkheadRestrictor("test", 1, 2, time.Second)
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		var mutex sync.Mutex
+		var order []int
+		var wg sync.WaitGroup
+		for i := 1; i <= 2; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ok, err := r.Check(context.Background())
+				assert.True(t, ok)
+				assert.NoError(t, err)
+
+				mutex.Lock()
+				order = append(order, i)
+				mutex.Unlock()
+				r.Defer()
+			}(i)
+			waitForWaiters(r, i)
+		}
+
+		r.Defer()
+		wg.Wait()
+		assert.Equal(t, []int{1, 2}, order)
+		assert.Equal(t, int64(1), r.available)
+	})
+
+	t.Run("times out on max wait", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewBulkheadRestrictor("test", 1, 1, 10*time.Millisecond, WithClock(clk))
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		done := check(r, context.Background())
+		waitForTimers(clk, 1)
+
+		clk.Advance(9 * time.Millisecond)
+		select {
+		case <-done:
+			t.Fatal("must wait until the max wait")
+		default:
+		}
+
+		clk.Advance(time.Millisecond)
+		res := <-done
+		assert.False(t, res.ok)
+		assert.Error(t, res.err)
+		assert.IsType(t, &WaitTimeoutError{}, res.err)
+		assert.Equal(t, 0, r.waiters.Len())
+	})
+
+	t.Run("cancels with context", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewBulkheadRestrictor("test", 1, 1, time.Second, WithClock(clk))
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := clk.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		done := check(r, ctx)
+		waitForTimers(clk, 2)
+		clk.Advance(10 * time.Millisecond)
+
+		res := <-done
+		assert.False(t, res.ok)
+		assert.Equal(t, context.DeadlineExceeded, res.err)
+		assert.Equal(t, 0, r.waiters.Len())
+	})
+}
+
+// waitForWaiters waits until the given number of checks are waiting in the
+// queue of the restrictor
+func waitForWaiters(r *BulkheadRestrictor, n int) {
+	for {
+		r.mutex.Lock()
+		waiters := r.waiters.Len()
+		r.mutex.Unlock()
+
+		if waiters >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBulkheadRestrictor_Release(t *testing.T) {
+	r, _ := NewBulkheadRestrictor("test", 1, 0, time.Second)
+	_, err := r.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), r.available)
+	r.Release()
+	assert.Equal(t, int64(1), r.available)
+
+	t.Run("holds no permits on rejections", func(t *testing.T) {
+		_, err := r.Check(context.Background())
+		require.NoError(t, err)
+
+		_, err = r.Check(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, int64(0), r.available)
+
+		r.Release()
+		assert.Equal(t, int64(1), r.available)
+	})
+}
+
+func TestBulkheadRestrictor_Defer(t *testing.T) {
+	r, _ := NewBulkheadRestrictor("test", 1, 0, time.Second)
+	_, err := r.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), r.available)
+	r.Defer()
+	assert.Equal(t, int64(1), r.available)
+}
@@ -4,7 +4,10 @@
 
 package restrictor
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // InvalidOptionError is a error tyoe for options
 type InvalidOptionError struct {
@@ -33,3 +36,63 @@ func (e *ThresholdError) Error() string {
 		e.Threshold,
 	)
 }
+
+// RateLimitError is a error type for exceeded invocation rates
+type RateLimitError struct {
+	Name  string
+	Rate  float64
+	Burst int64
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf(
+		"token bucket restriction(%s) rate limit reached / rate: %g per second, burst: %d",
+		e.Name,
+		e.Rate,
+		e.Burst,
+	)
+}
+
+// QueueFullError is a error type for full bulkhead wait queues
+type QueueFullError struct {
+	Name  string
+	Queue int64
+}
+
+func (e *QueueFullError) Error() string {
+	return fmt.Sprintf(
+		"bulkhead restriction(%s) wait queue is full / queue: %d",
+		e.Name,
+		e.Queue,
+	)
+}
+
+// WaitTimeoutError is a error type for timed out waits on bulkhead queues
+type WaitTimeoutError struct {
+	Name     string
+	Duration time.Duration
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf(
+		"bulkhead restriction(%s) wait timed out / duration: %s",
+		e.Name,
+		e.Duration,
+	)
+}
+
+// LoadSheddingError is a error type for shed invocations
+type LoadSheddingError struct {
+	Name        string
+	Priority    Priority
+	Utilisation float64
+}
+
+func (e *LoadSheddingError) Error() string {
+	return fmt.Sprintf(
+		"priority restriction(%s) shed the %s priority invocation / utilisation: %.2f%%",
+		e.Name,
+		e.Priority,
+		e.Utilisation,
+	)
+}
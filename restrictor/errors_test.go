@@ -2,6 +2,7 @@ package restrictor
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,41 @@ func TestThresholdError(t *testing.T) {
 	assert.Error(t, err)
 	assert.EqualError(t, err, "concurrent run restriction(test) threshold reached / runs: 10")
 }
+
+func TestRateLimitError(t *testing.T) {
+	err := &RateLimitError{
+		Name:  "test",
+		Rate:  2.5,
+		Burst: 10,
+	}
+	assert.Error(t, err)
+	assert.EqualError(t, err, "token bucket restriction(test) rate limit reached / rate: 2.5 per second, burst: 10")
+}
+
+func TestQueueFullError(t *testing.T) {
+	err := &QueueFullError{
+		Name:  "test",
+		Queue: 10,
+	}
+	assert.Error(t, err)
+	assert.EqualError(t, err, "bulkhead restriction(test) wait queue is full / queue: 10")
+}
+
+func TestWaitTimeoutError(t *testing.T) {
+	err := &WaitTimeoutError{
+		Name:     "test",
+		Duration: 50 * time.Millisecond,
+	}
+	assert.Error(t, err)
+	assert.EqualError(t, err, "bulkhead restriction(test) wait timed out / duration: 50ms")
+}
+
+func TestLoadSheddingError(t *testing.T) {
+	err := &LoadSheddingError{
+		Name:        "test",
+		Priority:    PrioritySheddable,
+		Utilisation: 75,
+	}
+	assert.Error(t, err)
+	assert.EqualError(t, err, "priority restriction(test) shed the sheddable priority invocation / utilisation: 75.00%")
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is an algorithm which learns the concurrency limit from the observed
+// invocation samples
+type Limit interface {
+	// Limit returns the current concurrency limit
+	Limit() int64
+
+	// Update adjusts the limit with the given invocation sample
+	Update(inflight int64, latency time.Duration, failed bool)
+}
+
+// AIMDLimit is an additive increase multiplicative decrease limit algorithm,
+// it increases the limit by one on healthy invocations and multiplies the limit
+// with the backoff ratio on failures and slow invocations
+type AIMDLimit struct {
+	mutex sync.RWMutex
+
+	limit, min, max int64
+
+	backoff float64
+	timeout time.Duration
+}
+
+// NewAIMDLimit inits a new AIMD limit, the invocations longer than the given
+// timeout are considered as congestion as well as the failures
+func NewAIMDLimit(initial, min, max int64, backoff float64, timeout time.Duration) (*AIMDLimit, error) {
+	if err := validateLimits(initial, min, max); err != nil {
+		return nil, err
+	}
+
+	if backoff <= 0.0 || backoff >= 1.0 {
+		return nil, &InvalidOptionError{
+			Name: "aimd limit backoff ratio",
+			Type: "float greater than 0.0 and less than 1.0",
+		}
+	}
+
+	if timeout <= 0 {
+		return nil, &InvalidOptionError{
+			Name: "aimd limit timeout",
+			Type: "positive duration",
+		}
+	}
+
+	return &AIMDLimit{
+		limit:   initial,
+		min:     min,
+		max:     max,
+		backoff: backoff,
+		timeout: timeout,
+	}, nil
+}
+
+// Limit returns the current concurrency limit
+func (l *AIMDLimit) Limit() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.limit
+}
+
+// Update decreases the limit on congestion and increases the limit when the
+// inflight invocations use at least half of the limit
+func (l *AIMDLimit) Update(inflight int64, latency time.Duration, failed bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if failed || latency > l.timeout {
+		l.limit = maxInt64(l.min, int64(float64(l.limit)*l.backoff))
+		return
+	}
+
+	// Increase the limit only when the limit is actually in use
+	if inflight*2 >= l.limit {
+		l.limit = minInt64(l.max, l.limit+1)
+	}
+}
+
+const (
+	// gradientLimitSmoothing is the weight of the new limit on updates
+	gradientLimitSmoothing = 0.2
+
+	// gradientLimitProbeInterval is the number of samples to relearn the
+	// no-load latency
+	gradientLimitProbeInterval = 1000
+)
+
+// GradientLimit is a delay based limit algorithm similar to TCP Vegas, it
+// compares the no-load latency with the observed latency and adjusts the limit
+// with the gradient of the two
+type GradientLimit struct {
+	mutex sync.RWMutex
+
+	limit    float64
+	min, max int64
+
+	minLatency time.Duration
+	samples    int64
+}
+
+// NewGradientLimit inits a new gradient limit
+func NewGradientLimit(initial, min, max int64) (*GradientLimit, error) {
+	if err := validateLimits(initial, min, max); err != nil {
+		return nil, err
+	}
+
+	return &GradientLimit{
+		limit: float64(initial),
+		min:   min,
+		max:   max,
+	}, nil
+}
+
+// Limit returns the current concurrency limit
+func (l *GradientLimit) Limit() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return int64(l.limit)
+}
+
+// Update adjusts the limit with the gradient of the no-load latency and the
+// observed latency, the failures are considered as the highest congestion. The
+// successful samples without a measurable latency are skipped.
+func (l *GradientLimit) Update(inflight int64, latency time.Duration, failed bool) {
+	// A zero latency is below the clock resolution, it has no gradient
+	if !failed && latency <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Relearn the no-load latency periodically to adapt to the permanent
+	// latency changes
+	l.samples++
+	if l.samples%gradientLimitProbeInterval == 0 {
+		l.minLatency = 0
+	}
+
+	if latency > 0 && (l.minLatency == 0 || latency < l.minLatency) {
+		l.minLatency = latency
+	}
+
+	var limit float64
+	if failed {
+		// Back off without the queue allowance on failures
+		limit = l.limit * 0.5
+	} else {
+		gradient := math.Max(0.5, math.Min(1.0, float64(l.minLatency)/float64(latency)))
+
+		// Do not grow the limit when the limit is not in use
+		if gradient == 1.0 && inflight*2 < int64(l.limit) {
+			return
+		}
+
+		limit = l.limit*gradient + math.Sqrt(l.limit)
+	}
+	limit = l.limit*(1-gradientLimitSmoothing) + limit*gradientLimitSmoothing
+
+	l.limit = math.Max(float64(l.min), math.Min(float64(l.max), limit))
+}
+
+func validateLimits(initial, min, max int64) error {
+	if min < 1 {
+		return &InvalidOptionError{
+			Name: "min limit",
+			Type: "positive integer",
+		}
+	}
+
+	if max < min {
+		return &InvalidOptionError{
+			Name: "max limit",
+			Type: "integer greater than or equal to the min limit",
+		}
+	}
+
+	if initial < min || initial > max {
+		return &InvalidOptionError{
+			Name: "initial limit",
+			Type: "integer between the min and max limits",
+		}
+	}
+
+	return nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
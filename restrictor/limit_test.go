@@ -0,0 +1,173 @@
+package restrictor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	// Ensure the limit algorithms implement Limit on build
+	var _ Limit = (*AIMDLimit)(nil)
+	var _ Limit = (*GradientLimit)(nil)
+}
+
+func TestNewAIMDLimit(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		l, err := NewAIMDLimit(10, 1, 100, 0.9, time.Second)
+		assert.NoError(t, err)
+		assert.NotNil(t, l)
+		assert.Equal(t, int64(10), l.Limit())
+	})
+
+	t.Run("invalid min limit", func(t *testing.T) {
+		l, err := NewAIMDLimit(10, 0, 100, 0.9, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+
+	t.Run("invalid max limit", func(t *testing.T) {
+		l, err := NewAIMDLimit(10, 10, 5, 0.9, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+
+	t.Run("invalid initial limit", func(t *testing.T) {
+		l, err := NewAIMDLimit(200, 1, 100, 0.9, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+
+	t.Run("invalid backoff", func(t *testing.T) {
+		l, err := NewAIMDLimit(10, 1, 100, 1.0, time.Second)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		l, err := NewAIMDLimit(10, 1, 100, 0.9, 0)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+}
+
+func TestAIMDLimit_Update(t *testing.T) {
+	t.Run("increases on healthy invocations in use", func(t *testing.T) {
+		l, _ := NewAIMDLimit(10, 1, 11, 0.5, time.Second)
+		l.Update(5, time.Millisecond, false)
+		assert.Equal(t, int64(11), l.Limit())
+
+		// capped with the max limit
+		l.Update(10, time.Millisecond, false)
+		assert.Equal(t, int64(11), l.Limit())
+	})
+
+	t.Run("keeps the limit when not in use", func(t *testing.T) {
+		l, _ := NewAIMDLimit(10, 1, 100, 0.5, time.Second)
+		l.Update(1, time.Millisecond, false)
+		assert.Equal(t, int64(10), l.Limit())
+	})
+
+	t.Run("decreases on failures", func(t *testing.T) {
+		l, _ := NewAIMDLimit(10, 1, 100, 0.5, time.Second)
+		l.Update(10, time.Millisecond, true)
+		assert.Equal(t, int64(5), l.Limit())
+	})
+
+	t.Run("decreases on slow invocations", func(t *testing.T) {
+		l, _ := NewAIMDLimit(10, 4, 100, 0.5, time.Second)
+		l.Update(10, 2*time.Second, false)
+		assert.Equal(t, int64(5), l.Limit())
+
+		// capped with the min limit
+		l.Update(10, 2*time.Second, false)
+		assert.Equal(t, int64(4), l.Limit())
+	})
+}
+
+func TestNewGradientLimit(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		l, err := NewGradientLimit(10, 1, 100)
+		assert.NoError(t, err)
+		assert.NotNil(t, l)
+		assert.Equal(t, int64(10), l.Limit())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		l, err := NewGradientLimit(0, 1, 100)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, l)
+	})
+}
+
+func TestGradientLimit_Update(t *testing.T) {
+	t.Run("increases on healthy latency", func(t *testing.T) {
+		l, _ := NewGradientLimit(16, 1, 100)
+		for i := 0; i < 10; i++ {
+			l.Update(l.Limit(), 10*time.Millisecond, false)
+		}
+		assert.True(t, l.Limit() > 16)
+	})
+
+	t.Run("keeps the limit when not in use", func(t *testing.T) {
+		l, _ := NewGradientLimit(16, 1, 100)
+		for i := 0; i < 10; i++ {
+			l.Update(1, 10*time.Millisecond, false)
+		}
+		assert.Equal(t, int64(16), l.Limit())
+	})
+
+	t.Run("decreases on congestion", func(t *testing.T) {
+		l, _ := NewGradientLimit(64, 1, 100)
+		l.Update(64, 10*time.Millisecond, false)
+		limit := l.Limit()
+
+		for i := 0; i < 10; i++ {
+			l.Update(64, 100*time.Millisecond, false)
+		}
+		assert.True(t, l.Limit() < limit)
+	})
+
+	t.Run("decreases on failures", func(t *testing.T) {
+		l, _ := NewGradientLimit(64, 1, 100)
+		for i := 0; i < 10; i++ {
+			l.Update(64, 10*time.Millisecond, true)
+		}
+		assert.True(t, l.Limit() < 64)
+	})
+
+	t.Run("skips the successes without latency", func(t *testing.T) {
+		l, _ := NewGradientLimit(64, 1, 100)
+		l.Update(64, 10*time.Millisecond, false)
+		limit := l.Limit()
+
+		for i := 0; i < 10; i++ {
+			l.Update(64, 0, false)
+		}
+		assert.Equal(t, limit, l.Limit())
+		assert.Equal(t, 10*time.Millisecond, l.minLatency)
+
+		l.Update(64, 0, true)
+		assert.True(t, l.Limit() < limit)
+	})
+
+	t.Run("capped with min and max limits", func(t *testing.T) {
+		l, _ := NewGradientLimit(4, 2, 5)
+		for i := 0; i < 100; i++ {
+			l.Update(5, 10*time.Millisecond, false)
+		}
+		assert.Equal(t, int64(5), l.Limit())
+
+		for i := 0; i < 100; i++ {
+			l.Update(5, 10*time.Millisecond, true)
+		}
+		assert.Equal(t, int64(2), l.Limit())
+	})
+}
@@ -0,0 +1,35 @@
+package restrictor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		o, err := newOptions(nil)
+
+		assert.NoError(t, err)
+		assert.IsType(t, &clock.SystemClock{}, o.clock)
+	})
+
+	t.Run("with invalid option", func(t *testing.T) {
+		o, err := newOptions([]Option{WithClock(nil)})
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, o)
+	})
+}
+
+func TestWithClock(t *testing.T) {
+	clk := clocktest.NewClock(time.Now())
+	o, err := newOptions([]Option{WithClock(clk)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, clk, o.clock)
+}
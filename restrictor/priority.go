@@ -0,0 +1,55 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import "context"
+
+// Priority is an invocation priority for load shedding
+type Priority int8
+
+const (
+	// PrioritySheddable is the priority for the invocations which are shed
+	// first on high load
+	PrioritySheddable Priority = iota + 1
+
+	// PriorityNormal is the default priority for the invocations
+	PriorityNormal
+
+	// PriorityCritical is the priority for the invocations which are never
+	// shed until the capacity is full
+	PriorityCritical
+)
+
+type ctxKey string
+
+// ctxPriority holds priority context key
+const ctxPriority = ctxKey("priority")
+
+// WithPriority returns a copy of the context tagged with the given priority
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, ctxPriority, p)
+}
+
+// PriorityFromContext returns the priority of the context, the untagged
+// contexts have the normal priority
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(ctxPriority).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PrioritySheddable:
+		return "sheddable"
+	case PriorityNormal:
+		return "normal"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+)
+
+// PriorityMinRequests is the min number of the invocations in the stats of the
+// circuit breaker to count the failure ratio in the utilisation
+const PriorityMinRequests = 10
+
+// LoadFunc returns an external load percentage in [0.0, 100.0] range to shed
+// the invocations earlier when the dependency degrades
+type LoadFunc func(context.Context) float64
+
+// PriorityRestrictor is a restrictor which sheds the invocations by their
+// priorities depending on the utilisation. The utilisation is the greatest of
+// the concurrent run percentage over the capacity, the failure ratio of the
+// circuit breaker and the external load.
+type PriorityRestrictor struct {
+	name              string
+	current, capacity int64
+
+	sheddable, normal float64
+	load              LoadFunc
+
+	// stats returns the invocation counts of the circuit breaker, it is bound
+	// by the circuit breaker on init
+	stats func() (successes, failures uint32)
+}
+
+// NewPriorityRestrictor inits a new priority restrictor. The sheddable and
+// normal params are the max utilisation percentages to admit the invocations
+// with the sheddable and normal priorities, the critical invocations are
+// admitted until the capacity is full. The load func is optional. The circuit
+// breaker binds its stats to the restrictor, so the failure ratio of the
+// invocations counts as the utilisation after PriorityMinRequests invocations.
+// A restrictor shouldn't be shared between the circuit breakers.
+//
+// Params with example:
+// capacity: 100, sheddable: 70.0, normal: 90.0
+// The above configuration means that:
+// Over 70 concurrent runs the sheddable invocations, over 90 concurrent runs
+// the normal invocations and over 100 concurrent runs the critical invocations
+// are rejected
+func NewPriorityRestrictor(name string, capacity int64, sheddable, normal float64, load LoadFunc) (*PriorityRestrictor, error) {
+	if capacity < 1 {
+		return nil, &InvalidOptionError{
+			Name: "priority restrictor capacity",
+			Type: "positive integer",
+		}
+	}
+
+	if normal <= 0.0 || normal > 100.0 {
+		return nil, &InvalidOptionError{
+			Name: "priority restrictor normal utilisation",
+			Type: "float greater than 0.0 and less than equal to 100.0",
+		}
+	}
+
+	if sheddable <= 0.0 || sheddable > normal {
+		return nil, &InvalidOptionError{
+			Name: "priority restrictor sheddable utilisation",
+			Type: "float greater than 0.0 and less than equal to normal utilisation",
+		}
+	}
+
+	return &PriorityRestrictor{
+		name:      name,
+		capacity:  capacity,
+		sheddable: sheddable,
+		normal:    normal,
+		load:      load,
+	}, nil
+}
+
+// Check checks if the current utilisation allows the priority of the
+// invocation
+func (r *PriorityRestrictor) Check(ctx context.Context) (bool, error) {
+	current := atomic.AddInt64(&r.current, 1)
+
+	utilisation := float64(current) / float64(r.capacity) * 100
+	if r.stats != nil {
+		utilisation = math.Max(utilisation, failureRatio(r.stats()))
+	}
+	if r.load != nil {
+		utilisation = math.Max(utilisation, r.load(ctx))
+	}
+
+	priority := PriorityFromContext(ctx)
+	if utilisation > r.threshold(priority) {
+		return false, &LoadSheddingError{
+			Name:        r.name,
+			Priority:    priority,
+			Utilisation: utilisation,
+		}
+	}
+	return true, nil
+}
+
+// BindStats binds the invocation counts of the circuit breaker to use its
+// failure ratio as the utilisation
+func (r *PriorityRestrictor) BindStats(stats func() (successes, failures uint32)) {
+	r.stats = stats
+}
+
+// Defer removes 1 from current runs
+func (r *PriorityRestrictor) Defer() {
+	atomic.AddInt64(&r.current, -1)
+}
+
+func (r *PriorityRestrictor) threshold(p Priority) float64 {
+	switch p {
+	case PrioritySheddable:
+		return r.sheddable
+	case PriorityCritical:
+		return 100.0
+	default:
+		return r.normal
+	}
+}
+
+// failureRatio returns the failure percentage of the invocations, it is zero
+// under the min requests
+func failureRatio(successes, failures uint32) float64 {
+	requests := successes + failures
+	if requests < PriorityMinRequests {
+		return 0.0
+	}
+	return float64(failures) / float64(requests) * 100
+}
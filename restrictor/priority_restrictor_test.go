@@ -0,0 +1,149 @@
+package restrictor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPriorityRestrictor(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		r, err := NewPriorityRestrictor("test", 10, 70.0, 90.0, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+		assert.IsType(t, &PriorityRestrictor{}, r)
+		assert.Equal(t, "test", r.name)
+		assert.Equal(t, int64(10), r.capacity)
+		assert.Equal(t, 70.0, r.sheddable)
+		assert.Equal(t, 90.0, r.normal)
+	})
+
+	t.Run("invalid capacity", func(t *testing.T) {
+		r, err := NewPriorityRestrictor("test", 0, 70.0, 90.0, nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid normal utilisation", func(t *testing.T) {
+		r, err := NewPriorityRestrictor("test", 10, 70.0, 101.0, nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid sheddable utilisation", func(t *testing.T) {
+		r, err := NewPriorityRestrictor("test", 10, 95.0, 90.0, nil)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestPriorityRestrictor_Check(t *testing.T) {
+	sheddable := WithPriority(context.Background(), PrioritySheddable)
+	normal := context.Background()
+	critical := WithPriority(context.Background(), PriorityCritical)
+
+	t.Run("sheds by concurrent runs", func(t *testing.T) {
+		r, _ := NewPriorityRestrictor("test", 10, 70.0, 90.0, nil)
+		for i := 0; i < 7; i++ {
+			_, err := r.Check(critical)
+			require.NoError(t, err)
+		}
+
+		// 80% utilisation
+		ok, err := r.Check(sheddable)
+		assert.False(t, ok)
+		assert.Error(t, err)
+		assert.IsType(t, &LoadSheddingError{}, err)
+		r.Defer()
+
+		ok, err = r.Check(normal)
+		assert.True(t, ok)
+		assert.NoError(t, err)
+
+		// 90% utilisation
+		ok, err = r.Check(normal)
+		assert.True(t, ok)
+		assert.NoError(t, err)
+
+		// 100% utilisation
+		ok, err = r.Check(normal)
+		assert.False(t, ok)
+		assert.IsType(t, &LoadSheddingError{}, err)
+		r.Defer()
+
+		ok, err = r.Check(critical)
+		assert.True(t, ok)
+		assert.NoError(t, err)
+
+		// over capacity
+		ok, err = r.Check(critical)
+		assert.False(t, ok)
+		assert.IsType(t, &LoadSheddingError{}, err)
+	})
+
+	t.Run("sheds by external load", func(t *testing.T) {
+		var load LoadFunc = func(context.Context) float64 {
+			return 80.0
+		}
+		r, _ := NewPriorityRestrictor("test", 10, 70.0, 90.0, load)
+
+		ok, err := r.Check(sheddable)
+		assert.False(t, ok)
+		assert.IsType(t, &LoadSheddingError{}, err)
+		r.Defer()
+
+		ok, err = r.Check(normal)
+		assert.True(t, ok)
+		assert.NoError(t, err)
+
+		ok, err = r.Check(critical)
+		assert.True(t, ok)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPriorityRestrictor_BindStats(t *testing.T) {
+	sheddable := WithPriority(context.Background(), PrioritySheddable)
+
+	tests := []struct {
+		name                string
+		successes, failures uint32
+		want                bool
+	}{
+		{"admits under the min requests", 0, PriorityMinRequests - 1, true},
+		{"admits under the failure ratio", 4, 6, true},
+		{"sheds over the failure ratio", 2, 8, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := NewPriorityRestrictor("test", 10, 70.0, 90.0, nil)
+			r.BindStats(func() (uint32, uint32) {
+				return test.successes, test.failures
+			})
+
+			ok, err := r.Check(sheddable)
+			assert.Equal(t, test.want, ok)
+			if !test.want {
+				assert.IsType(t, &LoadSheddingError{}, err)
+			}
+			r.Defer()
+		})
+	}
+}
+
+func TestPriorityRestrictor_Defer(t *testing.T) {
+	r, _ := NewPriorityRestrictor("test", 10, 70.0, 90.0, nil)
+	_, err := r.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), r.current)
+	r.Defer()
+	assert.Equal(t, int64(0), r.current)
+}
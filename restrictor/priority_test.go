@@ -0,0 +1,40 @@
+package restrictor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPriority(t *testing.T) {
+	ctx := WithPriority(context.Background(), PriorityCritical)
+	assert.Equal(t, PriorityCritical, ctx.Value(ctxPriority))
+}
+
+func TestPriorityFromContext(t *testing.T) {
+	t.Run("with tagged context", func(t *testing.T) {
+		ctx := WithPriority(context.Background(), PrioritySheddable)
+		assert.Equal(t, PrioritySheddable, PriorityFromContext(ctx))
+	})
+
+	t.Run("with untagged context", func(t *testing.T) {
+		assert.Equal(t, PriorityNormal, PriorityFromContext(context.Background()))
+	})
+}
+
+func TestPriority_String(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		want     string
+	}{
+		{PrioritySheddable, "sheddable"},
+		{PriorityNormal, "normal"},
+		{PriorityCritical, "critical"},
+		{Priority(0), "unknown"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.priority.String())
+	}
+}
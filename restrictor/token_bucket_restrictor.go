@@ -0,0 +1,132 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package restrictor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+)
+
+// TokenBucketRestrictor is a restrictor for invocation throughput, it refills
+// the bucket with the given rate per second and allows bursts up to the bucket
+// capacity
+type TokenBucketRestrictor struct {
+	mutex sync.Mutex
+
+	name  string
+	rate  float64
+	burst int64
+	wait  bool
+
+	tokens float64
+	last   time.Time
+	clock  clock.Clock
+}
+
+// NewTokenBucketRestrictor inits a new token bucket restrictor. The rate is the
+// number of tokens added to the bucket per second and the burst is the capacity
+// of the bucket. When the wait is enabled, the invocations wait for a token up
+// to the context deadline instead of being rejected immediately. The clock of
+// the refills and the waits can be set with the WithClock option.
+func NewTokenBucketRestrictor(name string, rate float64, burst int64, wait bool, opts ...Option) (*TokenBucketRestrictor, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, &InvalidOptionError{
+			Name: "token bucket rate",
+			Type: "positive float",
+		}
+	}
+
+	if burst < 1 {
+		return nil, &InvalidOptionError{
+			Name: "token bucket burst",
+			Type: "positive integer",
+		}
+	}
+
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenBucketRestrictor{
+		name:   name,
+		rate:   rate,
+		burst:  burst,
+		wait:   wait,
+		tokens: float64(burst),
+		last:   o.clock.Now(),
+		clock:  o.clock,
+	}, nil
+}
+
+// Check takes a token from the bucket if available, otherwise either rejects
+// the invocation or waits for the next token depending on the wait option
+func (r *TokenBucketRestrictor) Check(ctx context.Context) (bool, error) {
+	r.mutex.Lock()
+
+	now := r.clock.Now()
+	r.refill(now)
+
+	if r.tokens >= 1 {
+		r.tokens--
+		r.mutex.Unlock()
+		return true, nil
+	}
+
+	delay := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+	if !r.wait || !r.reachable(ctx, now.Add(delay)) {
+		r.mutex.Unlock()
+		return false, r.rateLimitError()
+	}
+
+	// Reserve the next token, so the following invocations wait in order
+	r.tokens--
+	r.mutex.Unlock()
+
+	timer := r.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true, nil
+	case <-ctx.Done():
+		// Give the reserved token back to the bucket
+		r.mutex.Lock()
+		r.tokens = math.Min(r.tokens+1, float64(r.burst))
+		r.mutex.Unlock()
+		return false, ctx.Err()
+	}
+}
+
+// Defer does nothing since the tokens are not given back to the bucket
+func (r *TokenBucketRestrictor) Defer() {}
+
+func (r *TokenBucketRestrictor) refill(now time.Time) {
+	elapsed := now.Sub(r.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	r.tokens = math.Min(r.tokens+elapsed*r.rate, float64(r.burst))
+	r.last = now
+}
+
+// reachable checks if the given time is before the context deadline
+func (r *TokenBucketRestrictor) reachable(ctx context.Context, at time.Time) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || !at.After(deadline)
+}
+
+func (r *TokenBucketRestrictor) rateLimitError() error {
+	return &RateLimitError{
+		Name:  r.name,
+		Rate:  r.rate,
+		Burst: r.burst,
+	}
+}
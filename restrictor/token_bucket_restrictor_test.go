@@ -0,0 +1,176 @@
+package restrictor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTokenBucketRestrictor(t *testing.T) {
+	t.Run("valid options", func(t *testing.T) {
+		r, err := NewTokenBucketRestrictor("test", 10, 5, true)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+		assert.IsType(t, &TokenBucketRestrictor{}, r)
+		assert.Equal(t, "test", r.name)
+		assert.Equal(t, 10.0, r.rate)
+		assert.Equal(t, int64(5), r.burst)
+		assert.Equal(t, 5.0, r.tokens)
+		assert.True(t, r.wait)
+		assert.IsType(t, &clock.SystemClock{}, r.clock)
+	})
+
+	t.Run("invalid clock", func(t *testing.T) {
+		r, err := NewTokenBucketRestrictor("test", 10, 5, true, WithClock(nil))
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		r, err := NewTokenBucketRestrictor("test", 0, 5, false)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("invalid burst", func(t *testing.T) {
+		r, err := NewTokenBucketRestrictor("test", 10, 0, false)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestTokenBucketRestrictor_Check(t *testing.T) {
+	t.Run("within burst", func(t *testing.T) {
+		r, _ := NewTokenBucketRestrictor("test", 1, 2, false)
+		for i := 0; i < 2; i++ {
+			ok, err := r.Check(context.Background())
+			assert.True(t, ok)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("over burst without wait", func(t *testing.T) {
+		r, _ := NewTokenBucketRestrictor("test", 1, 1, false)
+		_, _ = r.Check(context.Background())
+
+		ok, err := r.Check(context.Background())
+		assert.False(t, ok)
+		assert.Error(t, err)
+		assert.IsType(t, &RateLimitError{}, err)
+	})
+
+	t.Run("refills with rate", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewTokenBucketRestrictor("test", 100, 1, false, WithClock(clk))
+		_, _ = r.Check(context.Background())
+
+		clk.Advance(5 * time.Millisecond)
+		ok, err := r.Check(context.Background())
+		assert.False(t, ok)
+		assert.IsType(t, &RateLimitError{}, err)
+
+		clk.Advance(5 * time.Millisecond)
+		ok, err = r.Check(context.Background())
+		assert.True(t, ok)
+		assert.NoError(t, err)
+	})
+
+	t.Run("over burst with wait until the next token", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewTokenBucketRestrictor("test", 20, 1, true, WithClock(clk))
+		_, _ = r.Check(context.Background())
+
+		ctx, cancel := clk.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		done := check(r, ctx)
+		waitForTimers(clk, 2)
+
+		clk.Advance(49 * time.Millisecond)
+		select {
+		case <-done:
+			t.Fatal("must wait for the next token")
+		default:
+		}
+
+		clk.Advance(time.Millisecond)
+		res := <-done
+		assert.True(t, res.ok)
+		assert.NoError(t, res.err)
+	})
+
+	t.Run("over burst with wait beyond the deadline", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewTokenBucketRestrictor("test", 1, 1, true, WithClock(clk))
+		_, _ = r.Check(context.Background())
+
+		ctx, cancel := clk.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		ok, err := r.Check(ctx)
+		assert.False(t, ok)
+		assert.Error(t, err)
+		assert.IsType(t, &RateLimitError{}, err)
+		assert.Equal(t, 1, clk.Timers())
+	})
+
+	t.Run("over burst with wait and cancellation", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		r, _ := NewTokenBucketRestrictor("test", 1, 1, true, WithClock(clk))
+		_, _ = r.Check(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := check(r, ctx)
+		waitForTimers(clk, 1)
+		cancel()
+
+		res := <-done
+		assert.False(t, res.ok)
+		assert.Equal(t, context.Canceled, res.err)
+		assert.Equal(t, 0.0, r.tokens)
+		assert.Equal(t, 0, clk.Timers())
+	})
+}
+
+type checkResult struct {
+	ok  bool
+	err error
+}
+
+type checker interface {
+	Check(context.Context) (bool, error)
+}
+
+// check runs the check of the restrictor in a goroutine
+func check(r checker, ctx context.Context) <-chan checkResult {
+	done := make(chan checkResult, 1)
+	go func() {
+		ok, err := r.Check(ctx)
+		done <- checkResult{ok: ok, err: err}
+	}()
+	return done
+}
+
+// waitForTimers waits until the given number of timers are scheduled on the
+// clock
+func waitForTimers(clk *clocktest.Clock, n int) {
+	for clk.Timers() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTokenBucketRestrictor_Defer(t *testing.T) {
+	r, _ := NewTokenBucketRestrictor("test", 1, 1, false)
+	_, _ = r.Check(context.Background())
+	tokens := r.tokens
+
+	r.Defer()
+	assert.Equal(t, tokens, r.tokens)
+}
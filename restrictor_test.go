@@ -8,4 +8,9 @@ import (
 
 func TestRestrictor(t *testing.T) {
 	var _ Restrictor = (*restrictor.ConcurrentRunRestrictor)(nil)
+	var _ Restrictor = (*restrictor.TokenBucketRestrictor)(nil)
+	var _ Restrictor = (*restrictor.BulkheadRestrictor)(nil)
+	var _ PermitRestrictor = (*restrictor.BulkheadRestrictor)(nil)
+	var _ StatsRestrictor = (*restrictor.PriorityRestrictor)(nil)
+	var _ ReportingRestrictor = (*restrictor.AdaptiveRestrictor)(nil)
 }
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mustafaturan/shift/clock"
 	"github.com/mustafaturan/shift/counter"
 	"github.com/mustafaturan/shift/timer"
 )
@@ -29,6 +30,12 @@ type Shift struct {
 	// State of the circuit breaker. It can have open, half-open, close values
 	state State
 
+	// TransitionedAt is the time of the last state change
+	transitionedAt time.Time
+
+	// ResetAt is the time which the resetter fires on open state
+	resetAt time.Time
+
 	// Counter is behaviour for circuit breaker metrics which supports the basic
 	// increment and reset operations
 	counter Counter
@@ -36,26 +43,79 @@ type Shift struct {
 	// ResetTimer is a duration builder for resetting the state
 	resetTimer Timer
 
+	// Clock is the time source for the timeouts, timers and durations
+	clock clock.Clock
+
 	// Resetter holds the timer which resets the circuit breaker state
-	resetter *time.Timer
+	resetter clock.Timer
+
+	// Openings is the number of trips to open state, it identifies the
+	// current open state for the background health checks
+	openings uint64
+
+	// HealthCheck probes the dependency while the circuit breaker is open
+	healthCheck *healthCheck
+
+	// Shutdown rejects the new invocations when set, the done chan is closed
+	// to stop the background health checks
+	shutdown bool
+	done     chan struct{}
+
+	// Inflight tracks the invocations and the operators outliving their
+	// timeouts to wait on shutdown
+	inflight sync.WaitGroup
 
 	// Invokers holds invokers per state. Invokers are also
 	invokers map[State]invoker
 
-	// Trippers
-	halfOpenCloser SuccessHandler
-	halfOpenOpener FailureHandler
-	closeOpener    FailureHandler
+	// Criteria of the built-in trip policy
+	openers     map[State]*criteria
+	closer      *criteria
+	slowOpeners map[State]TripPolicy
+
+	// TripPolicy decides the target state after the invocations
+	tripPolicy TripPolicy
+
+	// Streak holds the consecutive successes and failures
+	streak streak
+
+	// Probes limits the in-flight invocations on half-open state
+	probes probes
+
+	// Ramp admits a growing percentage of invocations on half-open state
+	ramp ramp
 
 	successHandlers map[State][]SuccessHandler
 	failureHandlers map[State][]FailureHandler
 
+	// CustomOutcomeHandlers are callbacks for the custom outcomes of the
+	// classifiers
+	customOutcomeHandlers map[State][]CustomOutcomeHandler
+
+	// LatePanicHandlers are callbacks for the panics of the operators which
+	// outlive their invocations, the panics are logged without handlers
+	latePanicHandlers []PanicHandler
+
 	// Restrictors are pre-callback actions which applies right before the
 	// invocations. The restrictors can block the invocation with error returns.
 	restrictors []Restrictor
 
 	// StateChangeHandlers are callbacks which called on every state changes
 	stateChangeHandlers []StateChangeHandler
+
+	// HookedStateChangeHandlers are the state change handlers of the module
+	// packages, the options can't replace them
+	hookedStateChangeHandlers []StateChangeHandler
+
+	// Fallback is the default fallback for the failed invocations
+	fallback Fallback
+
+	// Classifier labels the invocation errors with outcomes
+	classifier ErrorClassifier
+
+	// CustomOutcomes tracks the custom outcomes of the classifier to report
+	// their counts in the stats
+	customOutcomes customOutcomes
 }
 
 const (
@@ -93,9 +153,10 @@ const (
 // New inits a new Circuit Breaker with given name and options
 func New(name string, opts ...Option) (*Shift, error) {
 	s := &Shift{
-		name:     name,
-		state:    optionDefaultInitialState,
-		resetter: time.AfterFunc(time.Microsecond, func() {}),
+		name:  name,
+		state: optionDefaultInitialState,
+		clock: clock.NewSystemClock(),
+		done:  make(chan struct{}),
 		invokers: map[State]invoker{
 			StateClose: &onCloseInvoker{
 				timeout: optionDefaultInvocationTimeout,
@@ -115,6 +176,18 @@ func New(name string, opts ...Option) (*Shift, error) {
 			StateHalfOpen: make([]SuccessHandler, 0),
 			StateOpen:     make([]SuccessHandler, 0),
 		},
+		customOutcomeHandlers: map[State][]CustomOutcomeHandler{
+			StateClose:    make([]CustomOutcomeHandler, 0),
+			StateHalfOpen: make([]CustomOutcomeHandler, 0),
+			StateOpen:     make([]CustomOutcomeHandler, 0),
+		},
+		openers: map[State]*criteria{
+			StateClose:    {},
+			StateHalfOpen: {},
+		},
+		closer:              &criteria{},
+		ramp:                ramp{random: defaultRandom},
+		slowOpeners:         make(map[State]TripPolicy),
 		stateChangeHandlers: make([]StateChangeHandler, 0),
 		restrictors:         make([]Restrictor, 0),
 	}
@@ -126,11 +199,22 @@ func New(name string, opts ...Option) (*Shift, error) {
 		}
 	}
 
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	s.transitionedAt = s.clock.Now()
+
+	// Init an inactive resetter
+	s.resetter = s.clock.AfterFunc(time.Microsecond, func() {})
+	s.resetter.Stop()
+
 	// Init the default counter if not specified
 	if s.counter == nil {
 		s.counter, _ = counter.NewTimeBucketCounter(
 			optionDefaultCounterCapacity,
 			optionDefaultCounterBucketDuration,
+			counter.WithClock(s.clock),
 		)
 	}
 
@@ -138,34 +222,88 @@ func New(name string, opts ...Option) (*Shift, error) {
 		s.resetTimer, _ = timer.NewConstantTimer(optionDefaultResetTimer)
 	}
 
-	s.invokers[StateClose].(*onCloseInvoker).timeoutCallback = func() {
-		s.counter.Increment(metricTimeout)
+	s.invokers[StateClose].(*onCloseInvoker).clock = s.clock
+	s.invokers[StateHalfOpen].(*onHalfOpenInvoker).clock = s.clock
+	s.invokers[StateClose].(*onCloseInvoker).inflight = &s.inflight
+	s.invokers[StateHalfOpen].(*onHalfOpenInvoker).inflight = &s.inflight
+	s.invokers[StateClose].(*onCloseInvoker).timeoutCallback = func(ctx context.Context) {
+		s.count(ctx, metricTimeout)
+	}
+	s.invokers[StateHalfOpen].(*onHalfOpenInvoker).timeoutCallback = func(ctx context.Context) {
+		s.count(ctx, metricTimeout)
+	}
+	if len(s.latePanicHandlers) > 0 {
+		s.invokers[StateClose].(*onCloseInvoker).latePanicCallback = s.runLatePanicCallbacks
+		s.invokers[StateHalfOpen].(*onHalfOpenInvoker).latePanicCallback = s.runLatePanicCallbacks
 	}
-	s.invokers[StateHalfOpen].(*onHalfOpenInvoker).timeoutCallback = func() {
-		s.counter.Increment(metricTimeout)
+	s.invokers[StateOpen].(*onOpenInvoker).rejectCallback = func(ctx context.Context) {
+		s.count(ctx, metricReject)
 	}
-	s.invokers[StateOpen].(*onOpenInvoker).rejectCallback = func() {
-		s.counter.Increment(metricReject)
+	s.invokers[StateClose].(*onCloseInvoker).slowCallback = func(ctx context.Context) {
+		s.count(ctx, metricSlow)
+	}
+	s.invokers[StateHalfOpen].(*onHalfOpenInvoker).slowCallback = func(ctx context.Context) {
+		s.count(ctx, metricSlow)
 	}
 
-	if s.closeOpener == nil {
+	if s.openers[StateClose].ratio == nil {
 		_ = WithOpener(StateClose, optionDefaultMinSuccessRatioForCloseOpener, optionDefaultMinRequests)(s)
 	}
-	s.failureHandlers[StateClose] = append([]FailureHandler{s.closeOpener}, s.failureHandlers[StateClose]...)
 
-	if s.halfOpenOpener == nil {
+	if s.openers[StateHalfOpen].ratio == nil {
 		_ = WithOpener(StateHalfOpen, optionDefaultMinSuccessRatioForHalfOpenOpener, optionDefaultMinRequests)(s)
 	}
-	s.failureHandlers[StateHalfOpen] = append([]FailureHandler{s.closeOpener}, s.failureHandlers[StateHalfOpen]...)
 
-	if s.halfOpenCloser == nil {
+	if s.closer.ratio == nil {
 		_ = WithCloser(optionDefaultMinSuccessRatioForHalfOpenCloser, optionDefaultMinRequests)(s)
 	}
-	s.successHandlers[StateHalfOpen] = append([]SuccessHandler{s.halfOpenCloser}, s.successHandlers[StateHalfOpen]...)
+
+	if s.tripPolicy == nil {
+		s.tripPolicy = s.builtInTripPolicy()
+	}
+
+	for _, r := range s.restrictors {
+		if sr, ok := r.(StatsRestrictor); ok {
+			sr.BindStats(s.invocationCounts)
+		}
+	}
+
+	if s.state.isHalfOpen() {
+		s.ramp.start(s.clock.Now())
+	}
 
 	return s, nil
 }
 
+// validate guards against the nonsensical combinations of the options
+func (s *Shift) validate() error {
+	i := s.invokers[StateClose].(*onCloseInvoker)
+	if i.slowThreshold > 0 && i.slowThreshold >= i.timeout {
+		return &InvalidOptionError{
+			Name:    "slow call threshold",
+			Message: "must be less than the invocation timeout",
+		}
+	}
+
+	// The window would drop the invocations before they time out
+	if c, ok := s.counter.(WindowCounter); ok && c.Window() < i.timeout {
+		return &InvalidOptionError{
+			Name:    "counter window",
+			Message: "must be longer than or equal to the invocation timeout",
+		}
+	}
+
+	// The circuit breaker would leave the open state before the in-flight
+	// invocations time out
+	if t, ok := s.resetTimer.(BoundedTimer); ok && t.Min() < i.timeout {
+		return &InvalidOptionError{
+			Name:    "reset timer",
+			Message: "must be longer than or equal to the invocation timeout",
+		}
+	}
+	return nil
+}
+
 // WithInitialState builds option to set initial state
 func WithInitialState(state State) Option {
 	return func(s *Shift) error {
@@ -183,6 +321,131 @@ func WithInvocationTimeout(duration time.Duration) Option {
 	}
 }
 
+// WithSlowCallThreshold builds option to set the latency bound for successful
+// invocations, the successful invocations which take longer than the given
+// duration are counted as slow calls in addition to successes
+func WithSlowCallThreshold(duration time.Duration) Option {
+	return func(s *Shift) error {
+		if duration <= 0 {
+			return &InvalidOptionError{
+				Name:    "slow call threshold",
+				Message: "must be positive duration",
+			}
+		}
+		s.invokers[StateClose].(*onCloseInvoker).slowThreshold = duration
+		s.invokers[StateHalfOpen].(*onHalfOpenInvoker).slowThreshold = duration
+		return nil
+	}
+}
+
+// WithHalfOpenMaxProbes builds option to set the max number of in-flight trial
+// invocations on half-open state. The invocations above the limit are rejected
+// with HalfOpenProbesExceededError and counted as rejections, so they do not
+// effect the success ratio on half-open state.
+func WithHalfOpenMaxProbes(n int64) Option {
+	return func(s *Shift) error {
+		if n < 1 {
+			return &InvalidOptionError{
+				Name:    "half-open max probes",
+				Message: "must be positive int",
+			}
+		}
+		s.probes.max = n
+		return nil
+	}
+}
+
+// WithHalfOpenRamp builds option to admit a growing percentage of invocations
+// on half-open state. The percentage moves to the next stage on every step
+// duration and the circuit breaker trips to close state only after reaching the
+// last stage. The invocations above the percentage are rejected with
+// HalfOpenRampRejectedError and counted as rejections.
+//
+// Params with example:
+// step: 10 * time.Second, percents: 5.0, 25.0, 50.0, 100.0
+// The above configuration means that:
+// On 'half-open' state, it admits 5% of the invocations in the first 10
+// seconds, 25% in the next 10 seconds, 50% in the next 10 seconds and 100%
+// after 30 seconds
+func WithHalfOpenRamp(step time.Duration, percents ...float64) Option {
+	return func(s *Shift) error {
+		if step <= 0 {
+			return &InvalidOptionError{
+				Name:    "half-open ramp step",
+				Message: "must be positive duration",
+			}
+		}
+
+		if len(percents) == 0 {
+			return &InvalidOptionError{
+				Name:    "half-open ramp percents",
+				Message: "can't be empty",
+			}
+		}
+
+		var prev float64
+		for _, p := range percents {
+			if p <= prev || p > 100.0 {
+				return &InvalidOptionError{
+					Name:    "half-open ramp percents",
+					Message: "must be increasing and less than equal to 100.0",
+				}
+			}
+			prev = p
+		}
+
+		s.ramp.step = step
+		s.ramp.percents = percents
+		return nil
+	}
+}
+
+// WithHealthCheck builds option to probe the dependency with the given operator
+// in the background while the circuit breaker is open. The probes start when
+// the reset timer fires and run on every interval with the invocation timeout.
+// After the given number of consecutive successful probes, the circuit breaker
+// trips to the target state which is either 'half-open' or 'close'. The probes
+// are not counted in the stats.
+func WithHealthCheck(o Operator, interval time.Duration, successes uint32, target State) Option {
+	return func(s *Shift) error {
+		if o == nil {
+			return &InvalidOptionError{
+				Name:    "health check operator",
+				Message: "can't be nil",
+			}
+		}
+
+		if interval <= 0 {
+			return &InvalidOptionError{
+				Name:    "health check interval",
+				Message: "must be positive duration",
+			}
+		}
+
+		if successes < 1 {
+			return &InvalidOptionError{
+				Name:    "health check successes",
+				Message: "must be positive int",
+			}
+		}
+
+		if !target.isHalfOpen() && !target.isClose() {
+			return &InvalidOptionError{
+				Name:    "health check target state",
+				Message: "can only be 'half-open' or 'close' state",
+			}
+		}
+
+		s.healthCheck = &healthCheck{
+			operator:  o,
+			interval:  interval,
+			successes: successes,
+			target:    target,
+		}
+		return nil
+	}
+}
+
 // WithResetTimer builds option to set reset timer
 func WithResetTimer(t Timer) Option {
 	return func(s *Shift) error {
@@ -191,6 +454,21 @@ func WithResetTimer(t Timer) Option {
 	}
 }
 
+// WithClock builds option to set the time source of the circuit breaker, the
+// default counter uses the same clock
+func WithClock(c clock.Clock) Option {
+	return func(s *Shift) error {
+		if c == nil {
+			return &InvalidOptionError{
+				Name:    "clock",
+				Message: "can't be nil",
+			}
+		}
+		s.clock = c
+		return nil
+	}
+}
+
 // WithCounter builds option to set stats counter
 func WithCounter(c Counter) Option {
 	return func(s *Shift) error {
@@ -219,6 +497,37 @@ func WithRestrictors(restrictors ...Restrictor) Option {
 	}
 }
 
+// WithFallback builds option to set the default fallback which substitutes the
+// results of the rejected, timed out and failed invocations on Run
+func WithFallback(f Fallback) Option {
+	return func(s *Shift) error {
+		if f == nil {
+			return &InvalidOptionError{
+				Name:    "fallback",
+				Message: "can't be nil",
+			}
+		}
+		s.fallback = f
+		return nil
+	}
+}
+
+// WithErrorClassifier builds option to set error classifier which labels each
+// invocation error as success, failure, ignored or a custom category before the
+// counters and handlers run. Without a classifier, any error is a failure.
+func WithErrorClassifier(c ErrorClassifier) Option {
+	return func(s *Shift) error {
+		if c == nil {
+			return &InvalidOptionError{
+				Name:    "error classifier",
+				Message: "can't be nil",
+			}
+		}
+		s.classifier = c
+		return nil
+	}
+}
+
 // WithStateChangeHandlers builds option to set state change handlers, the
 // provided handlers will be evaluate in the given order as option
 func WithStateChangeHandlers(handlers ...StateChangeHandler) Option {
@@ -271,12 +580,50 @@ func WithFailureHandlers(state State, handlers ...FailureHandler) Option {
 	}
 }
 
+// WithCustomOutcomeHandlers builds option to set on custom outcome handlers,
+// the handlers receive the invocations labelled with the custom outcomes by the
+// error classifier and will be evaluated in the given order as option
+func WithCustomOutcomeHandlers(state State, handlers ...CustomOutcomeHandler) Option {
+	return func(s *Shift) error {
+		for _, h := range handlers {
+			if h == nil {
+				return &InvalidOptionError{
+					Name:    "custom outcome handler",
+					Message: "can't be nil",
+				}
+			}
+		}
+		s.customOutcomeHandlers[state] = append(s.customOutcomeHandlers[state], handlers...)
+		return nil
+	}
+}
+
+// WithLatePanicHandlers builds option to set late panic handlers, the handlers
+// receive the panics of the operators which happen after their invocations are
+// timed out or cancelled, since no caller is left to re-raise them
+func WithLatePanicHandlers(handlers ...PanicHandler) Option {
+	return func(s *Shift) error {
+		for _, h := range handlers {
+			if h == nil {
+				return &InvalidOptionError{
+					Name:    "late panic handler",
+					Message: "can't be nil",
+				}
+			}
+		}
+		s.latePanicHandlers = append(s.latePanicHandlers, handlers...)
+		return nil
+	}
+}
+
 // WithOpener builds an option to set the default failure criteria to trip to
 // 'open' state. (If the failure criteria matches then the circuit breaker
 // trips to the 'open' state.)
 //
-// As runtime behaviour, it prepends a failure handler for the given state to
-// trip circuit breaker into the 'open' state when the given thresholds reached.
+// As runtime behaviour, it sets a RatioOpenPolicy for the given state to trip
+// circuit breaker into the 'open' state when the given thresholds reached.
+// The criteria can be combined with the consecutive failures criteria using
+// the WithConsecutiveOpener option.
 //
 // Definitions of the params are
 // state: StateClose, StateHalfOpen
@@ -290,47 +637,77 @@ func WithFailureHandlers(state State, handlers ...FailureHandler) Option {
 // than or equal to 95% then will trip to 'open' state
 func WithOpener(state State, minSuccessRatio float32, minRequests uint32) Option {
 	return func(s *Shift) error {
-		if !state.isClose() && !state.isHalfOpen() {
-			return &InvalidOptionError{
-				Name:    "state for failure criteria",
-				Message: "can only be applied to 'close' and 'half open' states",
-			}
+		p, err := NewRatioOpenPolicy(state, minSuccessRatio, minRequests)
+		if err != nil {
+			return err
 		}
 
-		if minSuccessRatio <= 0.0 || minSuccessRatio > 100.0 {
-			return &InvalidOptionError{
-				Name:    "min success ratio to trip to 'open' state",
-				Message: "can be greater than 0.0 and less than equal to 100.0",
-			}
+		s.openers[state].ratio = p
+		return nil
+	}
+}
+
+// WithConsecutiveOpener builds an option to set the consecutive failures
+// criteria to trip to 'open' state. Unlike the ratio criteria, it does not
+// require min number of requests, so the low volume circuit breakers trip too.
+// The rejections neither extend nor break the streaks.
+//
+// Definitions of the params are
+// state: StateClose, StateHalfOpen
+// failures: number of failures in a row to trip the circuit breaker
+// combination: CombineAny, CombineAll to combine with the ratio criteria
+//
+// Params with example:
+// state: StateClose, failures: 5, combination: CombineAny
+// The above configuration means that:
+// On 'close' state, if it counts 5 failures in a row or matches the ratio
+// criteria then will trip to 'open' state
+func WithConsecutiveOpener(state State, failures uint32, combination Combination) Option {
+	return func(s *Shift) error {
+		p, err := NewConsecutiveOpenPolicy(state, failures)
+		if err != nil {
+			return err
 		}
 
-		if minRequests < 1 {
+		if !combination.isValid() {
 			return &InvalidOptionError{
-				Name:    "min requests to check success ratio",
-				Message: "must be positive int",
+				Name:    "combination of the 'open' state criteria",
+				Message: "can only be CombineAny or CombineAll",
 			}
 		}
 
-		var handler OnFailure = func(ctx context.Context, _ error) {
-			stats := ctx.Value(CtxStats).(Stats)
-			requests := stats.SuccessCount + stats.FailureCount - stats.RejectCount
-			if requests < minRequests {
-				return
-			}
-
-			ratio := float32(stats.SuccessCount) / float32(requests) * 100
-
-			if ratio < minSuccessRatio {
-				_ = s.Trip(StateOpen, &FailureThresholdReachedError{})
-			}
-		}
+		s.openers[state].consecutive = p
+		s.openers[state].combination = combination
+		return nil
+	}
+}
 
-		if state.isHalfOpen() {
-			s.halfOpenOpener = handler
-		} else {
-			s.closeOpener = handler
+// WithSlowCallOpener builds an option to set the slow call criteria to trip to
+// 'open' state. It requires the slow call threshold option to detect the slow
+// calls.
+//
+// As runtime behaviour, it sets a SlowCallOpenPolicy for the given state to
+// trip circuit breaker into the 'open' state when the given thresholds
+// reached. The slow call criteria take precedence over the other criteria.
+//
+// Definitions of the params are
+// state: StateClose, StateHalfOpen
+// maxSlowRatio: max slow call ratio to keep the Circuit Breaker as is
+// minRequests: min number of requests before checking the ratio
+//
+// Params with example:
+// state: StateClose, maxSlowRatio: 50%, minRequests: 10
+// The above configuration means that:
+// On 'close' state, at min 10 requests, if it calculates the slow call ratio
+// greater than 50% then will trip to 'open' state
+func WithSlowCallOpener(state State, maxSlowRatio float32, minRequests uint32) Option {
+	return func(s *Shift) error {
+		p, err := NewSlowCallOpenPolicy(state, maxSlowRatio, minRequests)
+		if err != nil {
+			return err
 		}
 
+		s.slowOpeners[state] = p
 		return nil
 	}
 }
@@ -339,8 +716,8 @@ func WithOpener(state State, minSuccessRatio float32, minRequests uint32) Option
 // 'close' state. (If the success criteria matches then the circuit breaker
 // trips to the 'close' state.)
 //
-// As runtime behaviour, it appends a success handler for the given state to
-// trip circuit breaker into the 'close' state when the given thresholds reached
+// As runtime behaviour, it sets a RatioClosePolicy to trip circuit breaker
+// into the 'close' state when the given thresholds reached
 //
 // Definitions of the params are
 // state: StateHalfOpen(always half-open it is a hidden param)
@@ -354,35 +731,79 @@ func WithOpener(state State, minSuccessRatio float32, minRequests uint32) Option
 // will trip to 'close' state
 func WithCloser(minSuccessRatio float32, minRequests uint32) Option {
 	return func(s *Shift) error {
-		if minSuccessRatio <= 0.0 || minSuccessRatio > 100.0 {
-			return &InvalidOptionError{
-				Name:    "min success ratio to trip to 'close' state",
-				Message: "can be greater than 0.0 and less than equal to 100.0",
-			}
+		p, err := NewRatioClosePolicy(minSuccessRatio, minRequests)
+		if err != nil {
+			return err
 		}
 
-		if minRequests < 1 {
+		s.closer.ratio = p
+		return nil
+	}
+}
+
+// WithConsecutiveCloser builds an option to set the consecutive successes
+// criteria to trip from 'half-open' to 'close' state. The rejections neither
+// extend nor break the streaks.
+//
+// Definitions of the params are
+// successes: number of successes in a row to trip the circuit breaker
+// combination: CombineAny, CombineAll to combine with the ratio criteria
+//
+// Params with example:
+// successes: 3, combination: CombineAny
+// The above configuration means that:
+// On 'half-open' state, if it counts 3 successes in a row or matches the ratio
+// criteria then will trip to 'close' state
+func WithConsecutiveCloser(successes uint32, combination Combination) Option {
+	return func(s *Shift) error {
+		p, err := NewConsecutiveClosePolicy(successes)
+		if err != nil {
+			return err
+		}
+
+		if !combination.isValid() {
 			return &InvalidOptionError{
-				Name:    "min requests to check success ratio",
-				Message: "must be positive int",
+				Name:    "combination of the 'close' state criteria",
+				Message: "can only be CombineAny or CombineAll",
 			}
 		}
 
-		var handler OnSuccess = func(ctx context.Context, _ interface{}) {
-			stats := ctx.Value(CtxStats).(Stats)
-			requests := stats.SuccessCount + stats.FailureCount - stats.RejectCount
-			if requests < minRequests {
-				return
-			}
+		s.closer.consecutive = p
+		s.closer.combination = combination
+		return nil
+	}
+}
 
-			ratio := float32(stats.SuccessCount) / float32(requests) * 100
-			if ratio >= minSuccessRatio {
-				_ = s.Trip(StateClose)
+// WithTripPolicy builds an option to set the trip policy which decides the
+// target state after the invocations. It overrides the built-in trip policy
+// which is configured with the opener and the closer options.
+func WithTripPolicy(p TripPolicy) Option {
+	return func(s *Shift) error {
+		if p == nil {
+			return &InvalidOptionError{
+				Name:    "trip policy",
+				Message: "can't be nil",
 			}
 		}
-
-		s.halfOpenCloser = handler
-
+		s.tripPolicy = p
 		return nil
 	}
 }
+
+// builtInTripPolicy builds the trip policy from the opener and the closer
+// criteria, the slow call criteria are evaluated first
+func (s *Shift) builtInTripPolicy() TripPolicy {
+	policies := make([]TripPolicy, 0, 5)
+	for _, state := range []State{StateClose, StateHalfOpen} {
+		if p, ok := s.slowOpeners[state]; ok {
+			policies = append(policies, p)
+		}
+	}
+
+	return AnyPolicy(append(
+		policies,
+		s.openers[StateClose].policy(),
+		s.openers[StateHalfOpen].policy(),
+		s.closer.policy(),
+	)...)
+}
@@ -2,13 +2,18 @@ package shift
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/mustafaturan/shift/counter"
 	"github.com/mustafaturan/shift/mock"
 	"github.com/mustafaturan/shift/restrictor"
+	"github.com/mustafaturan/shift/timer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -134,6 +139,312 @@ func TestWithInvocationTimeout(t *testing.T) {
 	assert.Equal(t, duration, s.invokers[StateHalfOpen].(*deadlineInvoker).timeout)
 }
 
+func TestWithSlowCallThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with invalid duration", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithSlowCallThreshold(0),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with duration exceeding the invocation timeout", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInvocationTimeout(500*time.Millisecond),
+			WithSlowCallThreshold(time.Second),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		duration := 2 * time.Second
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithSlowCallThreshold(duration),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, duration, s.invokers[StateClose].(*deadlineInvoker).slowThreshold)
+		assert.Equal(t, duration, s.invokers[StateHalfOpen].(*deadlineInvoker).slowThreshold)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	window, err := counter.NewSlidingWindowCounter(5, 100*time.Millisecond, counter.DefaultMetrics)
+	require.NoError(t, err)
+	resetTimer, err := timer.NewConstantTimer(time.Second)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		invalid string
+	}{
+		{
+			name:    "with counter window shorter than the invocation timeout",
+			opts:    []Option{WithCounter(window), WithInvocationTimeout(time.Second)},
+			invalid: "counter window",
+		},
+		{
+			name:    "with reset timer shorter than the invocation timeout",
+			opts:    []Option{WithResetTimer(resetTimer), WithInvocationTimeout(2 * time.Second)},
+			invalid: "reset timer",
+		},
+		{
+			name: "with counter window and reset timer covering the invocation timeout",
+			opts: []Option{
+				WithCounter(window),
+				WithResetTimer(resetTimer),
+				WithInvocationTimeout(500 * time.Millisecond),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New(name, test.opts...)
+			if test.invalid == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, s)
+				return
+			}
+
+			var optionErr *InvalidOptionError
+			require.True(t, errors.As(err, &optionErr))
+			assert.Equal(t, test.invalid, optionErr.Name)
+			assert.Nil(t, s)
+		})
+	}
+}
+
+func TestWithHalfOpenMaxProbes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with invalid max probes", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithHalfOpenMaxProbes(0),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithHalfOpenMaxProbes(3),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), s.probes.max)
+	})
+}
+
+func TestWithHalfOpenRamp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	tests := []struct {
+		desc     string
+		step     time.Duration
+		percents []float64
+	}{
+		{"with invalid step", 0, []float64{50.0, 100.0}},
+		{"without percents", time.Second, nil},
+		{"with non-increasing percents", time.Second, []float64{50.0, 50.0}},
+		{"with percent above 100", time.Second, []float64{50.0, 100.1}},
+		{"with non-positive percent", time.Second, []float64{0.0, 100.0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			s, err := New(
+				name,
+				WithCounter(counter),
+				WithResetTimer(timer),
+				WithHalfOpenRamp(test.step, test.percents...),
+			)
+
+			assert.Error(t, err)
+			assert.IsType(t, &InvalidOptionError{}, err)
+			assert.Nil(t, s)
+		})
+	}
+
+	t.Run("with valid options", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithHalfOpenRamp(time.Second, 5.0, 25.0, 100.0),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Second, s.ramp.step)
+		assert.Equal(t, []float64{5.0, 25.0, 100.0}, s.ramp.percents)
+	})
+}
+
+func TestWithHealthCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	var probe Operate = func(context.Context) (interface{}, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		desc      string
+		o         Operator
+		interval  time.Duration
+		successes uint32
+		target    State
+	}{
+		{"with a nil operator", nil, time.Second, 3, StateHalfOpen},
+		{"with invalid interval", probe, 0, 3, StateHalfOpen},
+		{"with invalid successes", probe, time.Second, 0, StateHalfOpen},
+		{"with invalid target state", probe, time.Second, 3, StateOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			s, err := New(
+				name,
+				WithCounter(counter),
+				WithResetTimer(timer),
+				WithHealthCheck(test.o, test.interval, test.successes, test.target),
+			)
+
+			assert.Error(t, err)
+			assert.IsType(t, &InvalidOptionError{}, err)
+			assert.Nil(t, s)
+		})
+	}
+
+	t.Run("with valid options", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithHealthCheck(probe, time.Second, 3, StateClose),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Second, s.healthCheck.interval)
+		assert.Equal(t, uint32(3), s.healthCheck.successes)
+		assert.Equal(t, StateClose, s.healthCheck.target)
+	})
+}
+
+func TestWithClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("with a nil clock", func(t *testing.T) {
+		s, err := New(name, WithClock(nil))
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		now := time.Now()
+		clk := clocktest.NewClock(now)
+		s, err := New(name, WithClock(clk))
+		require.NoError(t, err)
+
+		assert.Equal(t, clk, s.clock)
+		assert.Equal(t, clk, s.invokers[StateClose].(*onCloseInvoker).clock)
+		assert.Equal(t, clk, s.invokers[StateHalfOpen].(*onHalfOpenInvoker).clock)
+		assert.Equal(t, now, s.transitionedAt)
+
+		// the default counter drops the stale buckets on the clock
+		s.counter.Increment(metricSuccess)
+		assert.Equal(t, uint32(1), s.stats().SuccessCount)
+
+		clk.Advance(time.Duration(optionDefaultCounterCapacity) * optionDefaultCounterBucketDuration)
+		assert.Equal(t, uint32(0), s.stats().SuccessCount)
+	})
+
+	t.Run("resets to half-open state on the clock", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(time.Minute)
+
+		s, err := New(name, WithClock(clk), WithResetTimer(timer))
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+		assert.Equal(t, time.Minute, s.Snapshot().NextHalfOpenIn)
+
+		clk.Advance(59 * time.Second)
+		assert.Equal(t, StateOpen, s.currentState())
+		assert.Equal(t, time.Second, s.Snapshot().NextHalfOpenIn)
+
+		clk.Advance(time.Second)
+		assert.Equal(t, StateHalfOpen, s.currentState())
+	})
+
+	t.Run("times out the invocations on the clock", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		s, err := New(name, WithClock(clk), WithInvocationTimeout(time.Hour))
+		require.NoError(t, err)
+
+		timers := clk.Timers()
+		go func() {
+			for clk.Timers() == timers {
+				time.Sleep(time.Millisecond)
+			}
+			clk.Advance(time.Hour)
+		}()
+
+		var fn Operate = func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		_, err = s.Run(context.Background(), fn)
+
+		var timeoutErr *InvocationTimeoutError
+		assert.True(t, errors.As(err, &timeoutErr))
+		assert.Equal(t, uint32(1), s.stats().TimeoutCount)
+	})
+}
+
 func TestWithRestrictors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -141,13 +452,350 @@ func TestWithRestrictors(t *testing.T) {
 	timer := mock.NewMockTimer(ctrl)
 	counter := mock.NewMockCounter(ctrl)
 
-	t.Run("with a nil restrictor", func(t *testing.T) {
-		var restrictor Restrictor
+	t.Run("with a nil restrictor", func(t *testing.T) {
+		var restrictor Restrictor
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithRestrictors(restrictor),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		restrictor := &restrictor.ConcurrentRunRestrictor{}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithRestrictors(restrictor),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(s.restrictors))
+		assert.Equal(t, restrictor, s.restrictors[0])
+	})
+}
+
+func TestWithFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil fallback", func(t *testing.T) {
+		var fallback Fallback
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithFallback(fallback),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+			return nil, nil
+		}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithFallback(fallback),
+		)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, s.fallback)
+	})
+}
+
+func TestWithErrorClassifier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil classifier", func(t *testing.T) {
+		var classifier ErrorClassifier
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		classifier := IgnoreContextCanceled()
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithErrorClassifier(classifier),
+		)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, s.classifier)
+	})
+}
+
+func TestWithTripPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil trip policy", func(t *testing.T) {
+		var policy TripPolicy
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithTripPolicy(policy),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		policy, _ := NewConsecutiveClosePolicy(3)
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithTripPolicy(policy),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, policy, s.tripPolicy)
+	})
+}
+
+func TestWithOnStateChangeHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil state change handler", func(t *testing.T) {
+		var validHandler OnStateChange = func(_, _ State, _ Stats) {}
+		var nilHandler StateChangeHandler
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithStateChangeHandlers(validHandler, nilHandler),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler1 OnStateChange = func(_, _ State, _ Stats) {}
+		var handler2 OnStateChange = func(_, _ State, _ Stats) {}
+		var handler3 OnStateChange = func(_, _ State, _ Stats) {}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithStateChangeHandlers(handler1, handler2, handler3),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(s.stateChangeHandlers))
+	})
+
+	t.Run("replaces the handlers", func(t *testing.T) {
+		var handler OnStateChange = func(_, _ State, _ Stats) {}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithStateChangeHandlers(handler, handler),
+			WithStateChangeHandlers(handler),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(s.stateChangeHandlers))
+	})
+}
+
+func TestWithSuccessHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil state change handler", func(t *testing.T) {
+		var validHandler OnSuccess = func(context.Context, interface{}) {}
+		var nilHandler SuccessHandler
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithSuccessHandlers(StateClose, validHandler, nilHandler),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler1 OnSuccess = func(context.Context, interface{}) {}
+		var handler2 OnSuccess = func(context.Context, interface{}) {}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithSuccessHandlers(StateClose, handler1, handler2),
+			WithSuccessHandlers(StateHalfOpen, handler1),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(s.successHandlers[StateClose]))
+		assert.Equal(t, 1, len(s.successHandlers[StateHalfOpen]))
+		assert.Equal(t, 0, len(s.successHandlers[StateOpen]))
+	})
+}
+
+func TestWithCustomOutcomeHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil custom outcome handler", func(t *testing.T) {
+		var validHandler OnCustomOutcome = func(context.Context, Outcome, error) {}
+		var nilHandler CustomOutcomeHandler
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithCustomOutcomeHandlers(StateClose, validHandler, nilHandler),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler1 OnCustomOutcome = func(context.Context, Outcome, error) {}
+		var handler2 OnCustomOutcome = func(context.Context, Outcome, error) {}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithCustomOutcomeHandlers(StateClose, handler1, handler2),
+			WithCustomOutcomeHandlers(StateHalfOpen, handler2),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(s.customOutcomeHandlers[StateClose]))
+		assert.Equal(t, 1, len(s.customOutcomeHandlers[StateHalfOpen]))
+		assert.Equal(t, 0, len(s.customOutcomeHandlers[StateOpen]))
+	})
+}
+
+func TestWithFailureHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with a nil state change handler", func(t *testing.T) {
+		var validHandler OnFailure = func(context.Context, error) {}
+		var nilHandler FailureHandler
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithFailureHandlers(StateClose, validHandler, nilHandler),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler1 OnFailure = func(context.Context, error) {}
+		var handler2 OnFailure = func(context.Context, error) {}
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithFailureHandlers(StateClose, handler1, handler2),
+			WithFailureHandlers(StateHalfOpen, handler2),
+			WithFailureHandlers(StateOpen, handler1),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(s.failureHandlers[StateClose]))
+		assert.Equal(t, 1, len(s.failureHandlers[StateHalfOpen]))
+		assert.Equal(t, 1, len(s.failureHandlers[StateOpen]))
+	})
+}
+
+func TestWithLatePanicHandlers(t *testing.T) {
+	t.Run("with a nil late panic handler", func(t *testing.T) {
+		var validHandler OnPanic = func(interface{}) {}
+		var nilHandler PanicHandler
+		s, err := New(name, WithLatePanicHandlers(validHandler, nilHandler))
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		var handler OnPanic = func(interface{}) {}
+		s, err := New(name, WithLatePanicHandlers(handler, handler))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(s.latePanicHandlers))
+		assert.NotNil(t, s.invokers[StateClose].(*onCloseInvoker).latePanicCallback)
+		assert.NotNil(t, s.invokers[StateHalfOpen].(*onHalfOpenInvoker).latePanicCallback)
+	})
+}
+
+func TestWithOpener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timer := mock.NewMockTimer(ctrl)
+	counter := mock.NewMockCounter(ctrl)
+
+	t.Run("with invalid state", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithRestrictors(restrictor),
+			WithOpener(StateOpen, -1.0, 100),
 		)
 
 		assert.Error(t, err)
@@ -155,36 +803,25 @@ func TestWithRestrictors(t *testing.T) {
 		assert.Nil(t, s)
 	})
 
-	t.Run("with valid options", func(t *testing.T) {
-		restrictor := &restrictor.ConcurrentRunRestrictor{}
+	t.Run("with invalid threshold", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithRestrictors(restrictor),
+			WithOpener(StateClose, -1.0, 100),
 		)
 
-		assert.NoError(t, err)
-		assert.Equal(t, 1, len(s.restrictors))
-		assert.Equal(t, restrictor, s.restrictors[0])
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
 	})
-}
 
-func TestWithOnStateChangeHandlers(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	timer := mock.NewMockTimer(ctrl)
-	counter := mock.NewMockCounter(ctrl)
-
-	t.Run("with a nil state change handler", func(t *testing.T) {
-		var validHandler OnStateChange = func(_, _ State, _ Stats) {}
-		var nilHandler StateChangeHandler
+	t.Run("with invalid min requests", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithStateChangeHandlers(validHandler, nilHandler),
+			WithOpener(StateHalfOpen, 0.5, 0),
 		)
 
 		assert.Error(t, err)
@@ -193,36 +830,86 @@ func TestWithOnStateChangeHandlers(t *testing.T) {
 	})
 
 	t.Run("with valid options", func(t *testing.T) {
-		var handler1 OnStateChange = func(_, _ State, _ Stats) {}
-		var handler2 OnStateChange = func(_, _ State, _ Stats) {}
-		var handler3 OnStateChange = func(_, _ State, _ Stats) {}
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithStateChangeHandlers(handler1, handler2, handler3),
+			WithOpener(StateClose, 98.5, 100),
 		)
 
 		assert.NoError(t, err)
-		assert.Equal(t, 3, len(s.stateChangeHandlers))
+		assert.NotNil(t, s)
+		assert.NotNil(t, s.tripPolicy)
+
+		t.Run("execute without matching the min requests criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 95, FailureCount: 2, RejectCount: 1}
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateClose, s.currentState())
+		})
+
+		t.Run("execute without matching the success threshold criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 998, FailureCount: 2, RejectCount: 0}
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateClose, s.currentState())
+		})
+
+		t.Run("execute with matched criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 984, FailureCount: 16}
+			counter.
+				EXPECT().
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+				Return(map[string]uint32{"success": stats.SuccessCount, "failure": stats.FailureCount})
+
+			counter.
+				EXPECT().
+				Reset()
+
+			timer.
+				EXPECT().
+				Next(gomock.Any()).
+				Return(60 * time.Second)
+
+			// Trips to open state on matched criteria
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateOpen, s.currentState())
+		})
+	})
+
+	t.Run("with half-open criteria on half-open state", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithInitialState(StateHalfOpen),
+			WithOpener(StateClose, 98.5, 10),
+			WithOpener(StateHalfOpen, 50.0, 10),
+		)
+		require.NoError(t, err)
+
+		// The close state criteria would trip to open state
+		stats := Stats{SuccessCount: 7, FailureCount: 3}
+		s.evaluate(StateHalfOpen, stats, OutcomeFailure, nil)
+
+		assert.Equal(t, StateHalfOpen, s.currentState())
 	})
 }
 
-func TestWithSuccessHandlers(t *testing.T) {
+func TestWithConsecutiveOpener(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	timer := mock.NewMockTimer(ctrl)
 	counter := mock.NewMockCounter(ctrl)
 
-	t.Run("with a nil state change handler", func(t *testing.T) {
-		var validHandler OnSuccess = func(context.Context, interface{}) {}
-		var nilHandler SuccessHandler
+	t.Run("with invalid state", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithSuccessHandlers(StateClose, validHandler, nilHandler),
+			WithConsecutiveOpener(StateOpen, 5, CombineAny),
 		)
 
 		assert.Error(t, err)
@@ -230,40 +917,141 @@ func TestWithSuccessHandlers(t *testing.T) {
 		assert.Nil(t, s)
 	})
 
-	t.Run("with valid options", func(t *testing.T) {
-		var handler1 OnSuccess = func(context.Context, interface{}) {}
-		var handler2 OnSuccess = func(context.Context, interface{}) {}
+	t.Run("with invalid failures", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithSuccessHandlers(StateClose, handler1, handler2),
-			WithSuccessHandlers(StateHalfOpen, handler1),
+			WithConsecutiveOpener(StateClose, 0, CombineAny),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with invalid combination", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithConsecutiveOpener(StateClose, 5, Combination(42)),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with any combination", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithConsecutiveOpener(StateClose, 3, CombineAny),
 		)
 
 		assert.NoError(t, err)
-		assert.Equal(t, 2, len(s.successHandlers[StateClose]))
-		// Additional one from trippers
-		assert.Equal(t, 1+1, len(s.successHandlers[StateHalfOpen]))
-		assert.Equal(t, 0, len(s.successHandlers[StateOpen]))
+		assert.NotNil(t, s.tripPolicy)
+
+		t.Run("execute without matching the criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 1, FailureCount: 2, ConsecutiveFailures: 2}
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateClose, s.currentState())
+		})
+
+		t.Run("execute with matched consecutive criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 1, FailureCount: 3, ConsecutiveFailures: 3}
+			counter.
+				EXPECT().
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+				Return(map[string]uint32{"success": stats.SuccessCount, "failure": stats.FailureCount})
+
+			counter.
+				EXPECT().
+				Reset()
+
+			timer.
+				EXPECT().
+				Next(gomock.Any()).
+				Return(60 * time.Second)
+
+			// Trips to open state on matched criteria
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateOpen, s.currentState())
+		})
+	})
+
+	t.Run("with all combination", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithConsecutiveOpener(StateClose, 3, CombineAll),
+			WithOpener(StateClose, 50.0, 4),
+		)
+
+		assert.NoError(t, err)
+
+		t.Run("execute with only matched consecutive criteria", func(t *testing.T) {
+			stats := Stats{FailureCount: 3, ConsecutiveFailures: 3}
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateClose, s.currentState())
+		})
+
+		t.Run("execute with all matched criteria", func(t *testing.T) {
+			stats := Stats{FailureCount: 4, ConsecutiveFailures: 4}
+			counter.
+				EXPECT().
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+				Return(map[string]uint32{"failure": stats.FailureCount})
+
+			counter.
+				EXPECT().
+				Reset()
+
+			timer.
+				EXPECT().
+				Next(gomock.Any()).
+				Return(60 * time.Second)
+
+			// Trips to open state on matched criteria
+			s.evaluate(StateClose, stats, OutcomeFailure, nil)
+
+			assert.Equal(t, StateOpen, s.currentState())
+		})
 	})
 }
 
-func TestWithFailureHandlers(t *testing.T) {
+func TestWithConsecutiveCloser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	timer := mock.NewMockTimer(ctrl)
 	counter := mock.NewMockCounter(ctrl)
 
-	t.Run("with a nil state change handler", func(t *testing.T) {
-		var validHandler OnFailure = func(context.Context, error) {}
-		var nilHandler FailureHandler
+	t.Run("with invalid successes", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithFailureHandlers(StateClose, validHandler, nilHandler),
+			WithConsecutiveCloser(0, CombineAny),
+		)
+
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("with invalid combination", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithConsecutiveCloser(3, Combination(-1)),
 		)
 
 		assert.Error(t, err)
@@ -272,27 +1060,48 @@ func TestWithFailureHandlers(t *testing.T) {
 	})
 
 	t.Run("with valid options", func(t *testing.T) {
-		var handler1 OnFailure = func(context.Context, error) {}
-		var handler2 OnFailure = func(context.Context, error) {}
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithFailureHandlers(StateClose, handler1, handler2),
-			WithFailureHandlers(StateHalfOpen, handler2),
-			WithFailureHandlers(StateOpen, handler1),
+			WithInitialState(StateHalfOpen),
+			WithConsecutiveCloser(3, CombineAny),
 		)
 
 		assert.NoError(t, err)
-		// Additional one from trippers
-		assert.Equal(t, 2+1, len(s.failureHandlers[StateClose]))
-		// Additional one from trippers
-		assert.Equal(t, 1+1, len(s.failureHandlers[StateHalfOpen]))
-		assert.Equal(t, 1, len(s.failureHandlers[StateOpen]))
+		assert.NotNil(t, s.tripPolicy)
+
+		t.Run("execute without matching the criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 2, FailureCount: 2, ConsecutiveSuccesses: 2}
+			s.evaluate(StateHalfOpen, stats, OutcomeSuccess, nil)
+
+			assert.Equal(t, StateHalfOpen, s.currentState())
+		})
+
+		t.Run("execute with matched consecutive criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 3, FailureCount: 2, ConsecutiveSuccesses: 3}
+			counter.
+				EXPECT().
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+				Return(map[string]uint32{"success": stats.SuccessCount, "failure": stats.FailureCount})
+
+			counter.
+				EXPECT().
+				Reset()
+
+			timer.
+				EXPECT().
+				Reset()
+
+			// Trips to close state on matched criteria
+			s.evaluate(StateHalfOpen, stats, OutcomeSuccess, nil)
+
+			assert.Equal(t, StateClose, s.currentState())
+		})
 	})
 }
 
-func TestWithOpener(t *testing.T) {
+func TestWithSlowCallOpener(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -304,7 +1113,7 @@ func TestWithOpener(t *testing.T) {
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithOpener(StateOpen, -1.0, 100),
+			WithSlowCallOpener(StateOpen, 50.0, 10),
 		)
 
 		assert.Error(t, err)
@@ -312,12 +1121,12 @@ func TestWithOpener(t *testing.T) {
 		assert.Nil(t, s)
 	})
 
-	t.Run("with invalid threshold", func(t *testing.T) {
+	t.Run("with invalid ratio", func(t *testing.T) {
 		s, err := New(
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithOpener(StateClose, -1.0, 100),
+			WithSlowCallOpener(StateClose, 100.0, 10),
 		)
 
 		assert.Error(t, err)
@@ -330,7 +1139,7 @@ func TestWithOpener(t *testing.T) {
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithOpener(StateHalfOpen, 0.5, 0),
+			WithSlowCallOpener(StateHalfOpen, 50.0, 0),
 		)
 
 		assert.Error(t, err)
@@ -343,38 +1152,33 @@ func TestWithOpener(t *testing.T) {
 			name,
 			WithCounter(counter),
 			WithResetTimer(timer),
-			WithOpener(StateClose, 98.5, 100),
+			WithSlowCallOpener(StateClose, 50.0, 10),
 		)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, s)
-		assert.Equal(t, 1, len(s.failureHandlers[StateClose]))
-
-		handler := s.failureHandlers[StateClose][0]
+		assert.NotNil(t, s.tripPolicy)
 
 		t.Run("execute without matching the min requests criteria", func(t *testing.T) {
-			stats := Stats{SuccessCount: 95, FailureCount: 2, RejectCount: 1}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
-			handler.Handle(ctx, nil)
+			stats := Stats{SuccessCount: 9, SlowCount: 9}
+			s.evaluate(StateClose, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateClose, s.currentState())
 		})
 
-		t.Run("execute without matching the success threshold criteria", func(t *testing.T) {
-			stats := Stats{SuccessCount: 998, FailureCount: 2, RejectCount: 0}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
-			handler.Handle(ctx, nil)
+		t.Run("execute without matching the slow call ratio criteria", func(t *testing.T) {
+			stats := Stats{SuccessCount: 10, FailureCount: 10, SlowCount: 10}
+			s.evaluate(StateClose, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateClose, s.currentState())
 		})
 
 		t.Run("execute with matched criteria", func(t *testing.T) {
-			stats := Stats{SuccessCount: 984, FailureCount: 16}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
+			stats := Stats{SuccessCount: 10, FailureCount: 2, RejectCount: 2, SlowCount: 6}
 			counter.
 				EXPECT().
-				Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
-				Return(map[string]uint32{"success": stats.SuccessCount, "failure": stats.FailureCount})
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
+				Return(map[string]uint32{"success": stats.SuccessCount, "slow": stats.SlowCount})
 
 			counter.
 				EXPECT().
@@ -382,15 +1186,77 @@ func TestWithOpener(t *testing.T) {
 
 			timer.
 				EXPECT().
-				Next(gomock.Any()).
+				Next(gomock.AssignableToTypeOf(&SlowCallThresholdReachedError{})).
 				Return(60 * time.Second)
 
 			// Trips to open state on matched criteria
-			handler.Handle(ctx, nil)
+			s.evaluate(StateClose, stats, OutcomeSuccess, nil)
+
+			assert.Equal(t, StateOpen, s.currentState())
+		})
+
+		t.Run("execute on another state", func(t *testing.T) {
+			stats := Stats{SuccessCount: 10, SlowCount: 10}
+			s.evaluate(StateClose, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateOpen, s.currentState())
 		})
 	})
+
+	t.Run("on half-open state", func(t *testing.T) {
+		s, err := New(
+			name,
+			WithCounter(counter),
+			WithResetTimer(timer),
+			WithSlowCallOpener(StateHalfOpen, 50.0, 10),
+		)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, s.tripPolicy)
+		assert.Equal(t, 0, len(s.successHandlers[StateClose]))
+	})
+}
+
+func TestEvaluateTripReasons(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	slowPolicy, err := NewSlowCallOpenPolicy(StateClose, 50.0, 10)
+	require.NoError(t, err)
+
+	var openOnSuccess TripPolicyFunc = func(state State, _ Stats, outcome Outcome) State {
+		if outcome == OutcomeSuccess {
+			return StateOpen
+		}
+		return state
+	}
+
+	tests := []struct {
+		desc   string
+		policy TripPolicy
+		stats  Stats
+		reason interface{}
+	}{
+		{"with the reason of the policy", AnyPolicy(slowPolicy), Stats{SuccessCount: 10, SlowCount: 6}, &SlowCallThresholdReachedError{}},
+		{"without a reason on success", openOnSuccess, Stats{SuccessCount: 10, SlowCount: 6}, &PolicyTrippedError{}},
+		{"without a slow call on success", AnyPolicy(slowPolicy, openOnSuccess), Stats{SuccessCount: 10}, &PolicyTrippedError{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			timer := mock.NewMockTimer(ctrl)
+			s, err := New(name, WithResetTimer(timer), WithTripPolicy(test.policy))
+			require.NoError(t, err)
+
+			timer.
+				EXPECT().
+				Next(gomock.AssignableToTypeOf(test.reason)).
+				Return(60 * time.Second)
+
+			s.evaluate(StateClose, test.stats, OutcomeSuccess, nil)
+			assert.Equal(t, StateOpen, s.currentState())
+		})
+	}
 }
 
 func TestWithCloser(t *testing.T) {
@@ -437,32 +1303,27 @@ func TestWithCloser(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.NotNil(t, s)
-		assert.Equal(t, 1, len(s.successHandlers[StateHalfOpen]))
-
-		handler := s.successHandlers[StateHalfOpen][0]
+		assert.NotNil(t, s.tripPolicy)
 
 		t.Run("execute without matching the min requests criteria", func(t *testing.T) {
 			stats := Stats{SuccessCount: 98, FailureCount: 2, RejectCount: 1}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
-			handler.Handle(ctx, nil)
+			s.evaluate(StateHalfOpen, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateHalfOpen, s.currentState())
 		})
 
 		t.Run("execute without matching the min success ratio criteria", func(t *testing.T) {
 			stats := Stats{SuccessCount: 97, FailureCount: 3, RejectCount: 0}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
-			handler.Handle(ctx, nil)
+			s.evaluate(StateHalfOpen, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateHalfOpen, s.currentState())
 		})
 
 		t.Run("execute with matched criteria", func(t *testing.T) {
 			stats := Stats{SuccessCount: 98, FailureCount: 2}
-			ctx := context.WithValue(context.Background(), CtxStats, stats)
 			counter.
 				EXPECT().
-				Stats(metricSuccess, metricFailure, metricTimeout, metricReject).
+				Stats(metricSuccess, metricFailure, metricTimeout, metricReject, metricFallback, metricSlow).
 				Return(map[string]uint32{"success": stats.SuccessCount, "failure": stats.FailureCount})
 
 			counter.
@@ -474,7 +1335,7 @@ func TestWithCloser(t *testing.T) {
 				Reset()
 
 			// Trips to close state on success criteria
-			handler.Handle(ctx, nil)
+			s.evaluate(StateHalfOpen, stats, OutcomeSuccess, nil)
 
 			assert.Equal(t, StateClose, s.currentState())
 		})
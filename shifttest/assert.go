@@ -0,0 +1,116 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shifttest
+
+import (
+	"strings"
+
+	"github.com/mustafaturan/shift"
+)
+
+// TestingT is the subset of testing.TB used by the assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Transitioner is an interface for the types recording state changes like
+// Recorder and Harness
+type Transitioner interface {
+	Transitions() []Transition
+}
+
+// Stater is an interface for the types exposing a state like *shift.Shift and
+// Harness
+type Stater interface {
+	State() shift.State
+}
+
+// AssertTransitions asserts the recorded state changes follow the given path
+// of states, a single state path asserts there is no state change
+func AssertTransitions(t TestingT, r Transitioner, path ...shift.State) bool {
+	t.Helper()
+
+	got := r.Transitions()
+
+	expected := make([]Transition, 0)
+	for i := 1; i < len(path); i++ {
+		expected = append(expected, Transition{From: path[i-1], To: path[i]})
+	}
+
+	if len(got) == len(expected) {
+		matched := true
+		for i := range got {
+			if got[i] != expected[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	t.Errorf(
+		"unexpected transitions:\n\texpected: %s\n\tactual  : %s",
+		formatPath(path),
+		formatTransitions(got),
+	)
+	return false
+}
+
+// AssertState asserts the current state
+func AssertState(t TestingT, s Stater, state shift.State) bool {
+	t.Helper()
+
+	if got := s.State(); got != state {
+		t.Errorf("unexpected state:\n\texpected: %s\n\tactual  : %s", state, got)
+		return false
+	}
+	return true
+}
+
+// AssertCallbacks asserts the number of recorded success and failure callbacks
+func AssertCallbacks(t TestingT, r *Recorder, successes, failures int) bool {
+	t.Helper()
+
+	gotSuccesses := len(r.EventsOf(EventSuccess))
+	gotFailures := len(r.EventsOf(EventFailure))
+	if gotSuccesses != successes || gotFailures != failures {
+		t.Errorf(
+			"unexpected callbacks:\n\texpected: %d successes, %d failures\n\tactual  : %d successes, %d failures",
+			successes,
+			failures,
+			gotSuccesses,
+			gotFailures,
+		)
+		return false
+	}
+	return true
+}
+
+func formatPath(path []shift.State) string {
+	states := make([]string, len(path))
+	for i, s := range path {
+		states[i] = s.String()
+	}
+	return strings.Join(states, "->")
+}
+
+func formatTransitions(transitions []Transition) string {
+	if len(transitions) == 0 {
+		return "none"
+	}
+
+	path := []shift.State{transitions[0].From}
+	for i, tr := range transitions {
+		// Mark the gaps between the recorded transitions
+		if i > 0 && transitions[i-1].To != tr.From {
+			path = append(path, tr.From)
+		}
+		path = append(path, tr.To)
+	}
+	return formatPath(path)
+}
@@ -0,0 +1,107 @@
+package shifttest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mustafaturan/shift"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+type transitions []Transition
+
+func (tr transitions) Transitions() []Transition {
+	return tr
+}
+
+type state shift.State
+
+func (s state) State() shift.State {
+	return shift.State(s)
+}
+
+func TestAssertTransitions(t *testing.T) {
+	recorded := transitions{
+		{From: shift.StateClose, To: shift.StateOpen},
+		{From: shift.StateOpen, To: shift.StateHalfOpen},
+	}
+
+	t.Run("on matching path", func(t *testing.T) {
+		ft := &fakeT{}
+
+		assert.True(t, AssertTransitions(ft, recorded, shift.StateClose, shift.StateOpen, shift.StateHalfOpen))
+		assert.Empty(t, ft.errors)
+	})
+
+	t.Run("on a different path", func(t *testing.T) {
+		ft := &fakeT{}
+
+		assert.False(t, AssertTransitions(ft, recorded, shift.StateClose, shift.StateOpen))
+		assert.Equal(
+			t,
+			[]string{"unexpected transitions:\n\texpected: close->open\n\tactual  : close->open->half-open"},
+			ft.errors,
+		)
+	})
+
+	t.Run("on a gap between transitions", func(t *testing.T) {
+		ft := &fakeT{}
+		recorded := transitions{
+			{From: shift.StateClose, To: shift.StateOpen},
+			{From: shift.StateHalfOpen, To: shift.StateClose},
+		}
+
+		assert.False(t, AssertTransitions(ft, recorded, shift.StateClose, shift.StateOpen, shift.StateClose))
+		assert.Equal(
+			t,
+			[]string{"unexpected transitions:\n\texpected: close->open->close\n\tactual  : close->open->half-open->close"},
+			ft.errors,
+		)
+	})
+
+	t.Run("without transitions", func(t *testing.T) {
+		ft := &fakeT{}
+
+		assert.True(t, AssertTransitions(ft, transitions{}, shift.StateClose))
+		assert.False(t, AssertTransitions(ft, transitions{}, shift.StateClose, shift.StateOpen))
+		assert.Equal(
+			t,
+			[]string{"unexpected transitions:\n\texpected: close->open\n\tactual  : none"},
+			ft.errors,
+		)
+	})
+}
+
+func TestAssertState(t *testing.T) {
+	ft := &fakeT{}
+
+	assert.True(t, AssertState(ft, state(shift.StateOpen), shift.StateOpen))
+	assert.False(t, AssertState(ft, state(shift.StateOpen), shift.StateClose))
+	assert.Equal(t, []string{"unexpected state:\n\texpected: close\n\tactual  : open"}, ft.errors)
+}
+
+func TestAssertCallbacks(t *testing.T) {
+	ft := &fakeT{}
+	r := NewRecorder()
+	r.record(Event{Type: EventSuccess})
+	r.record(Event{Type: EventFailure})
+	r.record(Event{Type: EventSuccess})
+
+	assert.True(t, AssertCallbacks(ft, r, 2, 1))
+	assert.False(t, AssertCallbacks(ft, r, 1, 1))
+	assert.Equal(
+		t,
+		[]string{"unexpected callbacks:\n\texpected: 1 successes, 1 failures\n\tactual  : 2 successes, 1 failures"},
+		ft.errors,
+	)
+}
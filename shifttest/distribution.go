@@ -0,0 +1,84 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shifttest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Distribution is an interface for the latency distributions of the scripts
+type Distribution interface {
+	// Next returns the next latency
+	Next() time.Duration
+}
+
+// DistributionFunc is a function type implementing Distribution
+type DistributionFunc func() time.Duration
+
+// Next implements Distribution for DistributionFunc
+func (fn DistributionFunc) Next() time.Duration {
+	return fn()
+}
+
+// ConstantLatency returns the given latency on every call
+func ConstantLatency(d time.Duration) Distribution {
+	return DistributionFunc(func() time.Duration {
+		return d
+	})
+}
+
+// SequenceLatency returns the given latencies in order and starts over after
+// the last one
+func SequenceLatency(latencies ...time.Duration) Distribution {
+	var mutex sync.Mutex
+	var i int
+	return DistributionFunc(func() time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if len(latencies) == 0 {
+			return 0
+		}
+		d := latencies[i%len(latencies)]
+		i++
+		return d
+	})
+}
+
+// UniformLatency returns uniformly distributed latencies in [min, max) with a
+// seeded source, so the same seed replays the same latencies
+func UniformLatency(min, max time.Duration, seed int64) Distribution {
+	var mutex sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	return DistributionFunc(func() time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(random.Int63n(int64(max-min)))
+	})
+}
+
+// NormalLatency returns normally distributed latencies with the given mean
+// and standard deviation with a seeded source, the negative latencies are
+// clamped to zero
+func NormalLatency(mean, stddev time.Duration, seed int64) Distribution {
+	var mutex sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	return DistributionFunc(func() time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		d := time.Duration(random.NormFloat64()*float64(stddev)) + mean
+		if d < 0 {
+			return 0
+		}
+		return d
+	})
+}
@@ -0,0 +1,68 @@
+package shifttest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantLatency(t *testing.T) {
+	d := ConstantLatency(time.Second)
+
+	assert.Equal(t, time.Second, d.Next())
+	assert.Equal(t, time.Second, d.Next())
+}
+
+func TestSequenceLatency(t *testing.T) {
+	t.Run("cycles the latencies", func(t *testing.T) {
+		d := SequenceLatency(time.Second, 2*time.Second)
+
+		assert.Equal(t, time.Second, d.Next())
+		assert.Equal(t, 2*time.Second, d.Next())
+		assert.Equal(t, time.Second, d.Next())
+	})
+
+	t.Run("without latencies", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), SequenceLatency().Next())
+	})
+}
+
+func TestUniformLatency(t *testing.T) {
+	t.Run("within the range", func(t *testing.T) {
+		d := UniformLatency(time.Second, 2*time.Second, 1)
+		for i := 0; i < 100; i++ {
+			latency := d.Next()
+			assert.True(t, latency >= time.Second && latency < 2*time.Second)
+		}
+	})
+
+	t.Run("replays with the same seed", func(t *testing.T) {
+		a := UniformLatency(0, time.Second, 7)
+		b := UniformLatency(0, time.Second, 7)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, a.Next(), b.Next())
+		}
+	})
+
+	t.Run("with an empty range", func(t *testing.T) {
+		assert.Equal(t, time.Second, UniformLatency(time.Second, time.Second, 1).Next())
+	})
+}
+
+func TestNormalLatency(t *testing.T) {
+	t.Run("replays with the same seed", func(t *testing.T) {
+		a := NormalLatency(time.Second, 100*time.Millisecond, 7)
+		b := NormalLatency(time.Second, 100*time.Millisecond, 7)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, a.Next(), b.Next())
+		}
+	})
+
+	t.Run("clamps the negative latencies", func(t *testing.T) {
+		d := NormalLatency(0, time.Second, 1)
+		for i := 0; i < 100; i++ {
+			assert.True(t, d.Next() >= 0)
+		}
+	})
+}
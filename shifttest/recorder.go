@@ -0,0 +1,146 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shifttest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mustafaturan/shift"
+	"github.com/mustafaturan/shift/internal/hook"
+)
+
+// EventType is a type for the recorded event types
+type EventType string
+
+const (
+	// EventStateChange is recorded on every state change
+	EventStateChange = EventType("state_change")
+
+	// EventSuccess is recorded on every success callback
+	EventSuccess = EventType("success")
+
+	// EventFailure is recorded on every failure callback
+	EventFailure = EventType("failure")
+)
+
+// Event is a recorded callback of the circuit breaker
+type Event struct {
+	Type EventType
+
+	// From and To are the states of the state changes
+	From, To shift.State
+
+	// State is the state of the invocation for the success and failure events
+	State shift.State
+
+	// Res is the result of the success events
+	Res interface{}
+
+	// Err is the error of the failure events
+	Err error
+
+	// Stats are the stats passed to the callback
+	Stats shift.Stats
+}
+
+// Transition is a recorded state change
+type Transition struct {
+	From, To shift.State
+}
+
+// Recorder captures the state changes, success and failure callbacks of the
+// circuit breakers in order
+type Recorder struct {
+	mutex sync.Mutex
+
+	events []Event
+}
+
+// NewRecorder inits a new recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Options builds the circuit breaker options which register the recorder as
+// the state change, success and failure handlers for all states
+func (r *Recorder) Options() []shift.Option {
+	var onStateChange shift.OnStateChange = func(from, to shift.State, stats shift.Stats) {
+		r.record(Event{Type: EventStateChange, From: from, To: to, Stats: stats})
+	}
+	var onSuccess shift.OnSuccess = func(ctx context.Context, res interface{}) {
+		state, stats := fromContext(ctx)
+		r.record(Event{Type: EventSuccess, State: state, Res: res, Stats: stats})
+	}
+	var onFailure shift.OnFailure = func(ctx context.Context, err error) {
+		state, stats := fromContext(ctx)
+		r.record(Event{Type: EventFailure, State: state, Err: err, Stats: stats})
+	}
+
+	// The handler is appended, so the WithStateChangeHandlers options of the
+	// circuit breaker are kept regardless of the order
+	appendHandler := hook.AppendStateChangeHandler.(func(shift.StateChangeHandler) shift.Option)
+	opts := []shift.Option{appendHandler(onStateChange)}
+	for _, state := range []shift.State{shift.StateClose, shift.StateHalfOpen, shift.StateOpen} {
+		opts = append(
+			opts,
+			shift.WithSuccessHandlers(state, onSuccess),
+			shift.WithFailureHandlers(state, onFailure),
+		)
+	}
+	return opts
+}
+
+// Events returns a copy of the recorded events
+func (r *Recorder) Events() []Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	events := make([]Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// EventsOf returns the recorded events of the given type
+func (r *Recorder) EventsOf(t EventType) []Event {
+	events := make([]Event, 0)
+	for _, e := range r.Events() {
+		if e.Type == t {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Transitions returns the recorded state changes
+func (r *Recorder) Transitions() []Transition {
+	events := r.EventsOf(EventStateChange)
+	transitions := make([]Transition, len(events))
+	for i, e := range events {
+		transitions[i] = Transition{From: e.From, To: e.To}
+	}
+	return transitions
+}
+
+// Reset drops the recorded events
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = nil
+}
+
+func (r *Recorder) record(e Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, e)
+}
+
+func fromContext(ctx context.Context) (shift.State, shift.Stats) {
+	state, _ := ctx.Value(shift.CtxState).(shift.State)
+	stats, _ := ctx.Value(shift.CtxStats).(shift.Stats)
+	return state, stats
+}
@@ -0,0 +1,77 @@
+package shifttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mustafaturan/shift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	s, err := shift.New("test", r.Options()...)
+	require.NoError(t, err)
+
+	var success shift.Operate = func(context.Context) (interface{}, error) {
+		return "ok", nil
+	}
+	var failure shift.Operate = func(context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+
+	_, _ = s.Run(context.Background(), success)
+	_, _ = s.Run(context.Background(), failure)
+	require.NoError(t, s.Trip(shift.StateOpen))
+	_, _ = s.Run(context.Background(), success)
+
+	events := r.Events()
+	require.Len(t, events, 4)
+
+	assert.Equal(t, EventSuccess, events[0].Type)
+	assert.Equal(t, shift.StateClose, events[0].State)
+	assert.Equal(t, "ok", events[0].Res)
+	assert.Equal(t, uint32(1), events[0].Stats.SuccessCount)
+
+	assert.Equal(t, EventFailure, events[1].Type)
+	assert.Equal(t, shift.StateClose, events[1].State)
+	assert.Error(t, events[1].Err)
+
+	assert.Equal(t, EventStateChange, events[2].Type)
+	assert.Equal(t, shift.StateClose, events[2].From)
+	assert.Equal(t, shift.StateOpen, events[2].To)
+
+	assert.Equal(t, EventFailure, events[3].Type)
+	assert.Equal(t, shift.StateOpen, events[3].State)
+	assert.IsType(t, &shift.InvocationError{}, events[3].Err)
+
+	assert.Len(t, r.EventsOf(EventFailure), 2)
+	assert.Equal(t, []Transition{{From: shift.StateClose, To: shift.StateOpen}}, r.Transitions())
+
+	t.Run("Reset", func(t *testing.T) {
+		r.Reset()
+
+		assert.Empty(t, r.Events())
+		assert.Empty(t, r.Transitions())
+	})
+}
+
+func TestRecorderKeepsStateChangeHandlers(t *testing.T) {
+	var changes int
+	var handler shift.OnStateChange = func(_, _ shift.State, _ shift.Stats) {
+		changes++
+	}
+
+	// the handlers of the circuit breaker are kept regardless of the order
+	r := NewRecorder()
+	opts := append(r.Options(), shift.WithStateChangeHandlers(handler))
+	s, err := shift.New("test", opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Trip(shift.StateOpen))
+
+	assert.Equal(t, 1, changes)
+	assert.Len(t, r.Transitions(), 1)
+}
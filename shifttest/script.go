@@ -0,0 +1,166 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shifttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mustafaturan/shift/clock/clocktest"
+)
+
+// Step is a scripted outcome of an invocation
+type Step struct {
+	// Res and Err are the returned values of the invocation
+	Res interface{}
+	Err error
+
+	// Latency is the duration which the invocation moves the clock forward
+	Latency time.Duration
+
+	// Panic is the value to panic with when it is not nil
+	Panic interface{}
+
+	// Timeout moves the clock to the deadline of the invocation and returns
+	// the context error
+	Timeout bool
+}
+
+// Succeed builds a step returning the given result
+func Succeed(res interface{}) Step {
+	return Step{Res: res}
+}
+
+// Fail builds a step returning the given error
+func Fail(err error) Step {
+	return Step{Err: err}
+}
+
+// Timeout builds a step exceeding the deadline of the invocation
+func Timeout() Step {
+	return Step{Timeout: true}
+}
+
+// Panic builds a step panicking with the given value
+func Panic(v interface{}) Step {
+	return Step{Panic: v}
+}
+
+// After returns a copy of the step which takes the given latency
+func (s Step) After(latency time.Duration) Step {
+	s.Latency = latency
+	return s
+}
+
+// Repeat repeats the given steps n times
+func Repeat(n int, steps ...Step) []Step {
+	res := make([]Step, 0, n*len(steps))
+	for i := 0; i < n; i++ {
+		res = append(res, steps...)
+	}
+	return res
+}
+
+// WithLatencies returns copies of the given steps with the latencies drawn
+// from the distribution
+func WithLatencies(d Distribution, steps ...Step) []Step {
+	res := make([]Step, len(steps))
+	for i, s := range steps {
+		res[i] = s.After(d.Next())
+	}
+	return res
+}
+
+// ScriptExhaustedError is an error type for the invocations after the last
+// step of a script
+type ScriptExhaustedError struct {
+	Steps int
+}
+
+func (e *ScriptExhaustedError) Error() string {
+	return fmt.Sprintf("script exhausted after %d steps", e.Steps)
+}
+
+// Script is an operator which plays the given steps in order on every
+// invocation, it moves the manual clock by the latencies of the steps instead
+// of sleeping
+type Script struct {
+	mutex sync.Mutex
+
+	clock *clocktest.Clock
+	steps []Step
+	calls int
+}
+
+// NewScript inits a scripted operator with the given clock and steps
+func NewScript(clock *clocktest.Clock, steps ...Step) *Script {
+	return &Script{clock: clock, steps: steps}
+}
+
+// Execute plays the next step of the script
+func (s *Script) Execute(ctx context.Context) (interface{}, error) {
+	step, ok := s.next()
+	if !ok {
+		return nil, &ScriptExhaustedError{Steps: len(s.steps)}
+	}
+
+	if step.Timeout {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil, context.DeadlineExceeded
+		}
+		s.clock.Set(deadline)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if step.Latency > 0 {
+		s.clock.Advance(step.Latency)
+	}
+
+	if step.Panic != nil {
+		panic(step.Panic)
+	}
+
+	// Honour the context like a real operator when the latency exceeds the
+	// deadline
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return step.Res, step.Err
+}
+
+// Calls returns the number of invocations
+func (s *Script) Calls() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.calls
+}
+
+// Remaining returns the number of steps to play
+func (s *Script) Remaining() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.calls >= len(s.steps) {
+		return 0
+	}
+	return len(s.steps) - s.calls
+}
+
+func (s *Script) next() (Step, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.calls
+	s.calls++
+	if i >= len(s.steps) {
+		return Step{}, false
+	}
+	return s.steps[i], true
+}
@@ -0,0 +1,95 @@
+package shifttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSteps(t *testing.T) {
+	err := errors.New("failed")
+
+	assert.Equal(t, Step{Res: "ok"}, Succeed("ok"))
+	assert.Equal(t, Step{Err: err}, Fail(err))
+	assert.Equal(t, Step{Timeout: true}, Timeout())
+	assert.Equal(t, Step{Panic: "boom"}, Panic("boom"))
+	assert.Equal(t, Step{Res: "ok", Latency: time.Second}, Succeed("ok").After(time.Second))
+	assert.Equal(t, []Step{Succeed(1), Fail(err), Succeed(1), Fail(err)}, Repeat(2, Succeed(1), Fail(err)))
+	assert.Equal(
+		t,
+		[]Step{Succeed(1).After(time.Second), Fail(err).After(2 * time.Second)},
+		WithLatencies(SequenceLatency(time.Second, 2*time.Second), Succeed(1), Fail(err)),
+	)
+}
+
+func TestScript(t *testing.T) {
+	failure := errors.New("failed")
+
+	t.Run("plays the steps in order", func(t *testing.T) {
+		clk := clocktest.NewClock(Epoch)
+		s := NewScript(clk, Succeed("ok").After(time.Second), Fail(failure))
+		assert.Equal(t, 2, s.Remaining())
+
+		res, err := s.Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res)
+		assert.Equal(t, Epoch.Add(time.Second), clk.Now())
+
+		res, err = s.Execute(context.Background())
+		assert.Equal(t, failure, err)
+		assert.Nil(t, res)
+
+		res, err = s.Execute(context.Background())
+		assert.Equal(t, &ScriptExhaustedError{Steps: 2}, err)
+		assert.EqualError(t, err, "script exhausted after 2 steps")
+		assert.Nil(t, res)
+
+		assert.Equal(t, 3, s.Calls())
+		assert.Equal(t, 0, s.Remaining())
+	})
+
+	t.Run("times out at the deadline", func(t *testing.T) {
+		clk := clocktest.NewClock(Epoch)
+		s := NewScript(clk, Timeout())
+
+		ctx, cancel := clk.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		res, err := s.Execute(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Nil(t, res)
+		assert.Equal(t, Epoch.Add(time.Second), clk.Now())
+	})
+
+	t.Run("times out without a deadline", func(t *testing.T) {
+		s := NewScript(clocktest.NewClock(Epoch), Timeout())
+
+		_, err := s.Execute(context.Background())
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("honours the deadline on latency", func(t *testing.T) {
+		clk := clocktest.NewClock(Epoch)
+		s := NewScript(clk, Succeed("ok").After(2*time.Second))
+
+		ctx, cancel := clk.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		res, err := s.Execute(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		s := NewScript(clocktest.NewClock(Epoch), Panic("boom"))
+
+		require.PanicsWithValue(t, "boom", func() {
+			_, _ = s.Execute(context.Background())
+		})
+	})
+}
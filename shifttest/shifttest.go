@@ -0,0 +1,94 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+// Package shifttest provides helpers to test the circuit breaker
+// configurations deterministically. The Harness drives a real circuit breaker
+// on a manual clock, the scripted operators move the clock by their latencies
+// instead of sleeping and the recorder captures every state change and
+// callback for the assertions.
+package shifttest
+
+import (
+	"context"
+	"time"
+
+	"github.com/mustafaturan/shift"
+	"github.com/mustafaturan/shift/clock/clocktest"
+)
+
+// Epoch is the initial time of the harness clocks
+var Epoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Harness is a real circuit breaker running on a manual clock with a recorder
+type Harness struct {
+	*shift.Shift
+
+	Clock    *clocktest.Clock
+	Recorder *Recorder
+}
+
+// Result is the outcome of an invocation run by the harness
+type Result struct {
+	Res interface{}
+	Err error
+
+	// Panic is the recovered value if the invocation panics
+	Panic interface{}
+}
+
+// New inits a circuit breaker with the given name and options on a manual
+// clock starting at the Epoch and registers a recorder
+func New(name string, opts ...shift.Option) (*Harness, error) {
+	return NewWithClock(clocktest.NewClock(Epoch), name, opts...)
+}
+
+// NewWithClock inits a circuit breaker with the given name and options on the
+// given manual clock and registers a recorder. The explicit counters need the
+// same clock to drop their stale buckets along with the harness clock.
+func NewWithClock(clk *clocktest.Clock, name string, opts ...shift.Option) (*Harness, error) {
+	r := NewRecorder()
+
+	options := append([]shift.Option{shift.WithClock(clk)}, opts...)
+	options = append(options, r.Options()...)
+
+	s, err := shift.New(name, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Harness{Shift: s, Clock: clk, Recorder: r}, nil
+}
+
+// Script inits a scripted operator on the harness clock
+func (h *Harness) Script(steps ...Step) *Script {
+	return NewScript(h.Clock, steps...)
+}
+
+// Invoke runs the given operator once and recovers the panics
+func (h *Harness) Invoke(ctx context.Context, o shift.Operator) (res Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = Result{Panic: r}
+		}
+	}()
+
+	r, err := h.Run(ctx, o)
+	return Result{Res: r, Err: err}
+}
+
+// Play runs the given script once per its remaining steps, the steps of the
+// rejected invocations are left to play later
+func (h *Harness) Play(ctx context.Context, s *Script) []Result {
+	n := s.Remaining()
+	results := make([]Result, 0, n)
+	for i := 0; i < n; i++ {
+		results = append(results, h.Invoke(ctx, s))
+	}
+	return results
+}
+
+// Transitions returns the recorded state changes
+func (h *Harness) Transitions() []Transition {
+	return h.Recorder.Transitions()
+}
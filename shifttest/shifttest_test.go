@@ -0,0 +1,147 @@
+package shifttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mustafaturan/shift"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/mustafaturan/shift/counter"
+	"github.com/mustafaturan/shift/timer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("with invalid options", func(t *testing.T) {
+		h, err := New("test", shift.WithClock(nil))
+
+		assert.Error(t, err)
+		assert.Nil(t, h)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		h, err := New("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "test", h.Name())
+		assert.Equal(t, Epoch, h.Clock.Now())
+		assert.Equal(t, Epoch, h.Snapshot().TransitionedAt)
+		assert.NotNil(t, h.Recorder)
+	})
+}
+
+func TestHarness(t *testing.T) {
+	failure := errors.New("failed")
+	resetTimer, err := timer.NewConstantTimer(10 * time.Second)
+	require.NoError(t, err)
+
+	h, err := New(
+		"test",
+		shift.WithResetTimer(resetTimer),
+		shift.WithInvocationTimeout(time.Second),
+		shift.WithSlowCallThreshold(500*time.Millisecond),
+		shift.WithOpener(shift.StateClose, 80.0, 4),
+		shift.WithCloser(100.0, 2),
+	)
+	require.NoError(t, err)
+
+	t.Run("trips to open state", func(t *testing.T) {
+		s := h.Script(
+			Succeed("ok").After(600*time.Millisecond),
+			Fail(failure),
+			Timeout(),
+			Panic("boom"),
+			Fail(failure),
+			Fail(failure),
+		)
+		results := h.Play(context.Background(), s)
+		require.Len(t, results, 6)
+
+		assert.Equal(t, "ok", results[0].Res)
+		assert.Error(t, results[1].Err)
+
+		var timeoutErr *shift.InvocationTimeoutError
+		assert.True(t, errors.As(results[2].Err, &timeoutErr))
+		assert.Equal(t, "boom", results[3].Panic)
+
+		// the panicking invocation is counted as a failure and trips the
+		// circuit breaker, the last ones are rejected without playing their
+		// steps
+		var openErr *shift.IsOnOpenStateError
+		assert.True(t, errors.As(results[4].Err, &openErr))
+		assert.True(t, errors.As(results[5].Err, &openErr))
+		assert.Equal(t, 4, s.Calls())
+		assert.Equal(t, 2, s.Remaining())
+
+		AssertState(t, h, shift.StateOpen)
+		AssertTransitions(t, h, shift.StateClose, shift.StateOpen)
+		AssertCallbacks(t, h.Recorder, 1, 5)
+
+		var panicErr *shift.OperatorPanicError
+		failures := h.Recorder.EventsOf(EventFailure)
+		assert.True(t, errors.As(failures[2].Err, &panicErr))
+		events := h.Recorder.EventsOf(EventStateChange)
+		assert.Equal(t, uint32(1), events[0].Stats.SuccessCount)
+		assert.Equal(t, uint32(3), events[0].Stats.FailureCount)
+		assert.Equal(t, uint32(1), events[0].Stats.TimeoutCount)
+		assert.Equal(t, uint32(1), events[0].Stats.SlowCount)
+	})
+
+	t.Run("resets to half-open state on the clock", func(t *testing.T) {
+		h.Clock.Advance(9 * time.Second)
+		AssertState(t, h, shift.StateOpen)
+
+		h.Clock.Advance(time.Second)
+		AssertState(t, h, shift.StateHalfOpen)
+	})
+
+	t.Run("trips to close state", func(t *testing.T) {
+		results := h.Play(context.Background(), h.Script(Repeat(2, Succeed("ok"))...))
+		require.Len(t, results, 2)
+
+		AssertTransitions(
+			t,
+			h,
+			shift.StateClose,
+			shift.StateOpen,
+			shift.StateHalfOpen,
+			shift.StateClose,
+		)
+	})
+}
+
+func TestHarnessWithSubSecondConfig(t *testing.T) {
+	clk := clocktest.NewClock(Epoch)
+	c, err := counter.NewTimeBucketCounter(10, 100*time.Millisecond, counter.WithClock(clk))
+	require.NoError(t, err)
+
+	resetTimer, err := timer.NewConstantTimer(500 * time.Millisecond)
+	require.NoError(t, err)
+
+	h, err := NewWithClock(
+		clk,
+		"test",
+		shift.WithCounter(c),
+		shift.WithResetTimer(resetTimer),
+		shift.WithInvocationTimeout(50*time.Millisecond),
+		shift.WithOpener(shift.StateClose, 50.0, 4),
+		shift.WithCloser(100.0, 2),
+	)
+	require.NoError(t, err)
+	assert.Same(t, clk, h.Clock)
+
+	// the failures of the previous second left the window
+	h.Play(context.Background(), h.Script(Repeat(3, Fail(errors.New("failed")))...))
+	clk.Advance(time.Second)
+	h.Play(context.Background(), h.Script(Succeed("ok"), Timeout(), Timeout(), Timeout()))
+	AssertState(t, h, shift.StateOpen)
+
+	clk.Advance(500 * time.Millisecond)
+	h.Play(context.Background(), h.Script(Repeat(2, Succeed("ok").After(10*time.Millisecond))...))
+
+	AssertTransitions(t, h, shift.StateClose, shift.StateOpen, shift.StateHalfOpen, shift.StateClose)
+	assert.Equal(t, Epoch.Add(1670*time.Millisecond), clk.Now())
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "context"
+
+// Shutdown stops the reset timer, the health checks and the counter, and
+// rejects the new invocations with ShutdownError. It waits for the in-flight
+// invocations including the operators outliving their timeouts until the
+// given context is done. Pass a cancelled context to skip waiting.
+func (s *Shift) Shutdown(ctx context.Context) error {
+	s.stop()
+	return s.wait(ctx)
+}
+
+// stop rejects the new invocations, releases the background resources and
+// stops the counter
+func (s *Shift) stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.shutdown {
+		return
+	}
+
+	s.shutdown = true
+	s.resetter.Stop()
+	close(s.done)
+
+	if c, ok := s.counter.(StoppableCounter); ok {
+		c.Stop()
+	}
+}
+
+// wait waits for the in-flight invocations until the given context is done
+func (s *Shift) wait(ctx context.Context) error {
+	waited := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enter registers an in-flight work unless the circuit breaker is shut down
+func (s *Shift) enter() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.shutdown {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// leave unregisters an in-flight work
+func (s *Shift) leave() {
+	s.inflight.Done()
+}
@@ -0,0 +1,159 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/mustafaturan/shift/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var success Operate = func(context.Context) (interface{}, error) {
+		return "welldone", nil
+	}
+
+	t.Run("rejects new invocations without fallback", func(t *testing.T) {
+		counter := mock.NewMockStoppableCounter(ctrl)
+		counter.EXPECT().Stop()
+
+		var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+			return "fallback", nil
+		}
+
+		s, err := New(name, WithCounter(counter), WithFallback(fallback))
+		require.NoError(t, err)
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		res, err := s.Run(context.Background(), success)
+		assert.Nil(t, res)
+		assert.Equal(t, &ShutdownError{Name: name}, err)
+	})
+
+	t.Run("rejects trips", func(t *testing.T) {
+		s, err := New(name)
+		require.NoError(t, err)
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		err = s.Trip(StateOpen)
+		assert.Equal(t, &ShutdownError{Name: name}, err)
+		assert.Equal(t, StateClose, s.currentState())
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		counter := mock.NewMockStoppableCounter(ctrl)
+		counter.EXPECT().Stop().Times(1)
+
+		s, err := New(name, WithCounter(counter))
+		require.NoError(t, err)
+
+		assert.NoError(t, s.Shutdown(context.Background()))
+		assert.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("freezes the window of the default counter", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		s, err := New(name, WithClock(clk))
+		require.NoError(t, err)
+
+		_, err = s.Run(context.Background(), success)
+		require.NoError(t, err)
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		clk.Advance(time.Minute)
+		assert.Equal(t, uint32(1), s.Stats().SuccessCount)
+	})
+
+	t.Run("stops the reset timer", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(10 * time.Millisecond)
+
+		s, err := New(name, WithResetTimer(timer))
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, StateOpen, s.currentState())
+	})
+
+	t.Run("stops the health checks", func(t *testing.T) {
+		timer := mock.NewMockTimer(ctrl)
+		timer.EXPECT().Next(gomock.Any()).Return(time.Millisecond)
+
+		var probes int32
+		var probe Operate = func(context.Context) (interface{}, error) {
+			atomic.AddInt32(&probes, 1)
+			return nil, errors.New("unhealthy")
+		}
+
+		s, err := New(
+			name,
+			WithResetTimer(timer),
+			WithHealthCheck(probe, time.Millisecond, 1, StateHalfOpen),
+		)
+		require.NoError(t, err)
+		require.NoError(t, s.Trip(StateOpen))
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&probes) > 0
+		}, time.Second, time.Millisecond)
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		probed := atomic.LoadInt32(&probes)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, probed, atomic.LoadInt32(&probes))
+	})
+
+	t.Run("waits for in-flight invocations", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		var blocking Operate = func(context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return "welldone", nil
+		}
+
+		s, err := New(name)
+		require.NoError(t, err)
+
+		go func() { _, _ = s.Run(context.Background(), blocking) }()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, s.Shutdown(ctx))
+
+		close(release)
+		assert.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("waits for the operators outliving their timeouts", func(t *testing.T) {
+		release := make(chan struct{})
+		var finished int32
+		var blocking Operate = func(context.Context) (interface{}, error) {
+			<-release
+			atomic.StoreInt32(&finished, 1)
+			return "welldone", nil
+		}
+
+		s, err := New(name, WithInvocationTimeout(time.Millisecond))
+		require.NoError(t, err)
+
+		_, err = s.Run(context.Background(), blocking)
+		require.Error(t, err)
+
+		close(release)
+		assert.NoError(t, s.Shutdown(context.Background()))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+	})
+}
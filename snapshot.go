@@ -0,0 +1,102 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "time"
+
+// Snapshot is an immutable point in time view of the circuit breaker
+type Snapshot struct {
+	// Name of the circuit breaker
+	Name string
+
+	// State of the circuit breaker
+	State State
+
+	// Stats of the invocations on the current state
+	Stats Stats
+
+	// TransitionedAt is the time of the last state change
+	TransitionedAt time.Time
+
+	// NextHalfOpenIn is the remaining duration until the reset timer fires on
+	// open state, zero on the other states and when a health check is
+	// configured since the health checks decide when to leave the open state
+	NextHalfOpenIn time.Duration
+
+	// Config holds the effective options of the circuit breaker
+	Config Config
+}
+
+// Config is the effective options of the circuit breaker
+type Config struct {
+	InvocationTimeout time.Duration
+	SlowCallThreshold time.Duration
+
+	HalfOpenMaxProbes    int64
+	HalfOpenRampStep     time.Duration
+	HalfOpenRampPercents []float64
+
+	HealthCheckInterval  time.Duration
+	HealthCheckSuccesses uint32
+	HealthCheckTarget    State
+
+	// Restrictors is the number of restrictors
+	Restrictors int
+
+	// Fallback and ErrorClassifier states if the options are set
+	Fallback, ErrorClassifier bool
+}
+
+// Snapshot returns an immutable view of the circuit breaker
+func (s *Shift) Snapshot() Snapshot {
+	// Read the state before the stats like the evaluations of the invocations
+	s.mutex.RLock()
+	state, transitionedAt, resetAt := s.state, s.transitionedAt, s.resetAt
+	s.mutex.RUnlock()
+
+	stats := s.stats()
+
+	var nextHalfOpenIn time.Duration
+	if state.isOpen() && s.healthCheck == nil {
+		if remaining := resetAt.Sub(s.clock.Now()); remaining > 0 {
+			nextHalfOpenIn = remaining
+		}
+	}
+
+	return Snapshot{
+		Name:           s.name,
+		State:          state,
+		Stats:          stats,
+		TransitionedAt: transitionedAt,
+		NextHalfOpenIn: nextHalfOpenIn,
+		Config:         s.config(),
+	}
+}
+
+// config returns the effective options of the circuit breaker
+func (s *Shift) config() Config {
+	closeInvoker := s.invokers[StateClose].(*onCloseInvoker)
+	c := Config{
+		InvocationTimeout: closeInvoker.timeout,
+		SlowCallThreshold: closeInvoker.slowThreshold,
+		HalfOpenMaxProbes: s.probes.max,
+		HalfOpenRampStep:  s.ramp.step,
+		Restrictors:       len(s.restrictors),
+		Fallback:          s.fallback != nil,
+		ErrorClassifier:   s.classifier != nil,
+	}
+
+	if s.ramp.enabled() {
+		c.HalfOpenRampPercents = append([]float64(nil), s.ramp.percents...)
+	}
+
+	if s.healthCheck != nil {
+		c.HealthCheckInterval = s.healthCheck.interval
+		c.HealthCheckSuccesses = s.healthCheck.successes
+		c.HealthCheckTarget = s.healthCheck.target
+	}
+
+	return c
+}
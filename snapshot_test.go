@@ -0,0 +1,93 @@
+package shift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mustafaturan/shift/clock/clocktest"
+	"github.com/mustafaturan/shift/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var probe Operate = func(context.Context) (interface{}, error) {
+		return nil, nil
+	}
+	var fallback FallbackFunc = func(context.Context, error) (interface{}, error) {
+		return nil, nil
+	}
+
+	timer := mock.NewMockTimer(ctrl)
+	s, err := New(
+		name,
+		WithResetTimer(timer),
+		WithInvocationTimeout(3*time.Second),
+		WithSlowCallThreshold(time.Second),
+		WithHalfOpenMaxProbes(5),
+		WithHalfOpenRamp(10*time.Second, 50.0, 100.0),
+		WithHealthCheck(probe, 2*time.Second, 3, StateClose),
+		WithFallback(fallback),
+	)
+	require.NoError(t, err)
+
+	t.Run("on close state", func(t *testing.T) {
+		snapshot := s.Snapshot()
+
+		assert.Equal(t, name, snapshot.Name)
+		assert.Equal(t, StateClose, snapshot.State)
+		assert.Equal(t, 100.0, snapshot.Stats.RampPercent)
+		assert.False(t, snapshot.TransitionedAt.IsZero())
+		assert.Equal(t, time.Duration(0), snapshot.NextHalfOpenIn)
+		assert.Equal(t, Config{
+			InvocationTimeout:    3 * time.Second,
+			SlowCallThreshold:    time.Second,
+			HalfOpenMaxProbes:    5,
+			HalfOpenRampStep:     10 * time.Second,
+			HalfOpenRampPercents: []float64{50.0, 100.0},
+			HealthCheckInterval:  2 * time.Second,
+			HealthCheckSuccesses: 3,
+			HealthCheckTarget:    StateClose,
+			Fallback:             true,
+		}, snapshot.Config)
+	})
+
+	t.Run("on open state", func(t *testing.T) {
+		before := s.Snapshot()
+		timer.EXPECT().Next(nil).Return(time.Minute)
+		require.NoError(t, s.Trip(StateOpen))
+
+		snapshot := s.Snapshot()
+		assert.Equal(t, StateOpen, snapshot.State)
+		assert.True(t, snapshot.TransitionedAt.After(before.TransitionedAt))
+		assert.Equal(t, time.Duration(0), snapshot.NextHalfOpenIn)
+		assert.Equal(t, 0.0, snapshot.Stats.RampPercent)
+	})
+
+	t.Run("on open state without health check", func(t *testing.T) {
+		clk := clocktest.NewClock(time.Now())
+		timer := mock.NewMockTimer(ctrl)
+		s, err := New(name, WithClock(clk), WithResetTimer(timer))
+		require.NoError(t, err)
+
+		timer.EXPECT().Next(nil).Return(time.Minute)
+		require.NoError(t, s.Trip(StateOpen))
+		clk.Advance(15 * time.Second)
+
+		snapshot := s.Snapshot()
+		assert.Equal(t, StateOpen, snapshot.State)
+		assert.Equal(t, 45*time.Second, snapshot.NextHalfOpenIn)
+	})
+
+	t.Run("is immutable", func(t *testing.T) {
+		snapshot := s.Snapshot()
+		snapshot.Config.HalfOpenRampPercents[0] = 1.0
+
+		assert.Equal(t, []float64{50.0, 100.0}, s.ramp.percents)
+	})
+}
@@ -4,24 +4,98 @@
 
 package shift
 
+import "github.com/mustafaturan/shift/histogram"
+
 const (
-	metricSuccess = "success"
-	metricFailure = "failure"
-	metricTimeout = "timeout"
-	metricReject  = "reject"
+	metricSuccess  = "success"
+	metricFailure  = "failure"
+	metricTimeout  = "timeout"
+	metricReject   = "reject"
+	metricFallback = "fallback"
+	metricSlow     = "slow"
 )
 
+// CustomOutcomePrefix namespaces the counter metrics of the custom outcomes, so
+// the custom outcomes can't inflate the built-in metrics
+const CustomOutcomePrefix = "custom:"
+
+// builtInMetrics are the metrics of the built-in outcomes and events
+var builtInMetrics = []string{
+	metricSuccess,
+	metricFailure,
+	metricTimeout,
+	metricReject,
+	metricFallback,
+	metricSlow,
+}
+
 // Stats is a structure which holds cb invocation metrics
 type Stats struct {
 	SuccessCount, FailureCount, TimeoutCount, RejectCount uint32
+
+	// FallbackCount is the number of fallback executions, the invocations
+	// with fallbacks are still counted as failures
+	FallbackCount uint32
+
+	// SlowCount is the number of successful invocations which took longer
+	// than the slow call threshold, they are also counted as successes
+	SlowCount uint32
+
+	// ConsecutiveSuccesses and ConsecutiveFailures are the streaks of the
+	// invocation outcomes in the current state
+	ConsecutiveSuccesses, ConsecutiveFailures uint32
+
+	// RampPercent is the admitted percentage of the invocations, it grows by
+	// the ramp stages on half-open state
+	RampPercent float64
+
+	// Latency is the summary of the invocation durations with p50, p90, p99,
+	// max and mean, it is only reported by the latency counters
+	Latency histogram.Summary
+
+	// custom holds the counts of the custom outcomes behind a pointer, so the
+	// stats stay comparable
+	custom *customCounts
+}
+
+// customCounts are the counts of the custom outcomes, they are immutable once
+// the stats are built
+type customCounts struct {
+	counts map[Outcome]uint32
+}
+
+// CustomCount returns the count of the given custom outcome of the error
+// classifiers
+func (s Stats) CustomCount(o Outcome) uint32 {
+	if s.custom == nil {
+		return 0
+	}
+	return s.custom.counts[o]
+}
+
+// CustomCounts returns a copy of the counts of the custom outcomes of the
+// error classifiers which are seen by the circuit breaker, it is nil without
+// custom outcomes
+func (s Stats) CustomCounts() map[Outcome]uint32 {
+	if s.custom == nil {
+		return nil
+	}
+
+	counts := make(map[Outcome]uint32, len(s.custom.counts))
+	for o, c := range s.custom.counts {
+		counts[o] = c
+	}
+	return counts
 }
 
 // newStats inits a new stats from given map
 func newStats(metrics map[string]uint32) Stats {
 	return Stats{
-		SuccessCount: metrics[metricSuccess],
-		FailureCount: metrics[metricFailure],
-		TimeoutCount: metrics[metricTimeout],
-		RejectCount:  metrics[metricReject],
+		SuccessCount:  metrics[metricSuccess],
+		FailureCount:  metrics[metricFailure],
+		TimeoutCount:  metrics[metricTimeout],
+		RejectCount:   metrics[metricReject],
+		FallbackCount: metrics[metricFallback],
+		SlowCount:     metrics[metricSlow],
 	}
 }
@@ -3,15 +3,18 @@ package shift
 import (
 	"testing"
 
+	"github.com/mustafaturan/shift/counter"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewStats(t *testing.T) {
 	metrics := map[string]uint32{
-		metricSuccess: 100,
-		metricFailure: 5,
-		metricTimeout: 3,
-		metricReject:  2,
+		metricSuccess:  100,
+		metricFailure:  5,
+		metricTimeout:  3,
+		metricReject:   2,
+		metricFallback: 1,
+		metricSlow:     4,
 	}
 
 	stats := newStats(metrics)
@@ -19,4 +22,43 @@ func TestNewStats(t *testing.T) {
 	assert.Equal(t, metrics[metricFailure], stats.FailureCount)
 	assert.Equal(t, metrics[metricTimeout], stats.TimeoutCount)
 	assert.Equal(t, metrics[metricReject], stats.RejectCount)
+	assert.Equal(t, metrics[metricFallback], stats.FallbackCount)
+	assert.Equal(t, metrics[metricSlow], stats.SlowCount)
+}
+
+func TestCounterDefaultMetrics(t *testing.T) {
+	// the slots of the sliding window counter must cover the metrics of stats
+	assert.Equal(
+		t,
+		[]string{
+			metricSuccess,
+			metricFailure,
+			metricTimeout,
+			metricReject,
+			metricFallback,
+			metricSlow,
+		},
+		counter.DefaultMetrics,
+	)
+}
+
+func TestStats_CustomCounts(t *testing.T) {
+	t.Run("without custom outcomes", func(t *testing.T) {
+		var stats Stats
+		assert.Equal(t, uint32(0), stats.CustomCount("client_error"))
+		assert.Nil(t, stats.CustomCounts())
+		assert.True(t, stats == Stats{})
+	})
+
+	t.Run("with custom outcomes", func(t *testing.T) {
+		stats := Stats{custom: &customCounts{counts: map[Outcome]uint32{"client_error": 2}}}
+		assert.Equal(t, uint32(2), stats.CustomCount("client_error"))
+		assert.Equal(t, uint32(0), stats.CustomCount("other"))
+
+		// the returned counts are copies
+		counts := stats.CustomCounts()
+		assert.Equal(t, map[Outcome]uint32{"client_error": 2}, counts)
+		counts["client_error"] = 5
+		assert.Equal(t, uint32(2), stats.CustomCount("client_error"))
+	})
 }
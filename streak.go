@@ -0,0 +1,36 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import "sync/atomic"
+
+// streak holds the number of consecutive successes and failures, a success
+// breaks the failure streak and a failure breaks the success streak
+type streak struct {
+	successes, failures uint32
+}
+
+// success extends the success streak
+func (s *streak) success() {
+	atomic.StoreUint32(&s.failures, 0)
+	atomic.AddUint32(&s.successes, 1)
+}
+
+// failure extends the failure streak
+func (s *streak) failure() {
+	atomic.StoreUint32(&s.successes, 0)
+	atomic.AddUint32(&s.failures, 1)
+}
+
+// reset breaks both streaks
+func (s *streak) reset() {
+	atomic.StoreUint32(&s.successes, 0)
+	atomic.StoreUint32(&s.failures, 0)
+}
+
+// load returns the consecutive successes and failures
+func (s *streak) load() (successes, failures uint32) {
+	return atomic.LoadUint32(&s.successes), atomic.LoadUint32(&s.failures)
+}
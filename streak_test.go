@@ -0,0 +1,28 @@
+package shift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreak(t *testing.T) {
+	var s streak
+
+	s.success()
+	s.success()
+	successes, failures := s.load()
+	assert.Equal(t, uint32(2), successes)
+	assert.Equal(t, uint32(0), failures)
+
+	s.failure()
+	successes, failures = s.load()
+	assert.Equal(t, uint32(0), successes)
+	assert.Equal(t, uint32(1), failures)
+
+	s.failure()
+	s.reset()
+	successes, failures = s.load()
+	assert.Equal(t, uint32(0), successes)
+	assert.Equal(t, uint32(0), failures)
+}
@@ -16,3 +16,10 @@ type Timer interface {
 	// Reset resets the current duration to the initial duration
 	Reset()
 }
+
+// BoundedTimer is a timer which knows its shortest duration, the shortest
+// duration can't be shorter than the invocation timeout
+type BoundedTimer interface {
+	Timer
+	Min() time.Duration
+}
@@ -14,10 +14,10 @@ type ConstantTimer struct {
 
 // NewConstantTimer inits ConstantTimer with the given duration
 func NewConstantTimer(duration time.Duration) (*ConstantTimer, error) {
-	if duration < time.Second {
+	if duration < MinDuration {
 		return nil, &InvalidOptionError{
 			Name: "constant timer duration",
-			Type: "positive duration(greater than or equal to a second)",
+			Type: "positive duration(greater than or equal to a millisecond)",
 		}
 	}
 	return &ConstantTimer{duration: duration}, nil
@@ -30,3 +30,8 @@ func (c *ConstantTimer) Next(_ error) time.Duration {
 
 // Reset sets the current duration to the initial duration
 func (c *ConstantTimer) Reset() {}
+
+// Min returns the shortest duration of the timer
+func (c *ConstantTimer) Min() time.Duration {
+	return c.duration
+}
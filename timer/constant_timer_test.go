@@ -9,7 +9,7 @@ import (
 
 func TestNewConstantTimer(t *testing.T) {
 	t.Run("with invalid duration", func(t *testing.T) {
-		timer, err := NewConstantTimer(5 * time.Millisecond)
+		timer, err := NewConstantTimer(5 * time.Microsecond)
 		assert.Nil(t, timer)
 		assert.Error(t, err)
 	})
@@ -20,6 +20,12 @@ func TestNewConstantTimer(t *testing.T) {
 		assert.NotNil(t, timer)
 		assert.Equal(t, 5*time.Second, timer.duration)
 	})
+
+	t.Run("with sub-second duration", func(t *testing.T) {
+		timer, err := NewConstantTimer(500 * time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, timer.Next(nil))
+	})
 }
 
 func TestNext(t *testing.T) {
@@ -33,3 +39,9 @@ func TestReset(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 5*time.Second, timer.duration)
 }
+
+func TestMin(t *testing.T) {
+	timer, err := NewConstantTimer(5 * time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timer.Min())
+}
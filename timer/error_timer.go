@@ -0,0 +1,88 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package timer
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// Matcher is a predicate func to check if the given error belongs to an error
+// class
+type Matcher func(error) bool
+
+// MatchIs builds a matcher which matches the errors using errors.Is with the
+// given target
+func MatchIs(target error) Matcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchAs builds a matcher which matches the errors using errors.As with the
+// given target. Same as errors.As, the target must be a non-nil pointer to
+// either a type that implements error, or to any interface type. The target is
+// only used as a type information, so it is never assigned.
+func MatchAs(target interface{}) Matcher {
+	typ := reflect.TypeOf(target).Elem()
+	return func(err error) bool {
+		return errors.As(err, reflect.New(typ).Interface())
+	}
+}
+
+// ErrorRule maps an error class to its own timer
+type ErrorRule struct {
+	Match Matcher
+	Timer Timer
+}
+
+// ErrorTimer picks the duration from the timer of the first matching error
+// rule and uses the fallback timer when none of the rules match the error
+type ErrorTimer struct {
+	rules    []ErrorRule
+	fallback Timer
+}
+
+// NewErrorTimer inits ErrorTimer with the given fallback timer and rules, the
+// rules are evaluated in the given order
+func NewErrorTimer(fallback Timer, rules ...ErrorRule) (*ErrorTimer, error) {
+	if fallback == nil {
+		return nil, &InvalidOptionError{
+			Name: "error timer fallback",
+			Type: "non-nil timer",
+		}
+	}
+
+	for _, r := range rules {
+		if r.Match == nil || r.Timer == nil {
+			return nil, &InvalidOptionError{
+				Name: "error timer rule",
+				Type: "non-nil matcher and timer pair",
+			}
+		}
+	}
+
+	return &ErrorTimer{rules: rules, fallback: fallback}, nil
+}
+
+// Next returns the duration from the timer of the first matching rule for the
+// given error or from the fallback timer
+func (t *ErrorTimer) Next(err error) time.Duration {
+	for _, r := range t.rules {
+		if r.Match(err) {
+			return r.Timer.Next(err)
+		}
+	}
+	return t.fallback.Next(err)
+}
+
+// Reset resets the timers of all rules and the fallback timer
+func (t *ErrorTimer) Reset() {
+	for _, r := range t.rules {
+		r.Timer.Reset()
+	}
+	t.fallback.Reset()
+}
@@ -0,0 +1,125 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maintenanceError struct{}
+
+func (e *maintenanceError) Error() string {
+	return "maintenance"
+}
+
+var errThreshold = errors.New("threshold")
+
+func TestMatchIs(t *testing.T) {
+	match := MatchIs(errThreshold)
+
+	assert.True(t, match(errThreshold))
+	assert.True(t, match(fmt.Errorf("wrapped: %w", errThreshold)))
+	assert.False(t, match(errors.New("another")))
+	assert.False(t, match(nil))
+}
+
+func TestMatchAs(t *testing.T) {
+	var target *maintenanceError
+	match := MatchAs(&target)
+
+	assert.True(t, match(&maintenanceError{}))
+	assert.True(t, match(fmt.Errorf("wrapped: %w", &maintenanceError{})))
+	assert.False(t, match(errThreshold))
+	assert.False(t, match(nil))
+	assert.Nil(t, target)
+}
+
+func TestNewErrorTimer(t *testing.T) {
+	fallback, err := NewConstantTimer(15 * time.Second)
+	require.NoError(t, err)
+
+	t.Run("with nil fallback", func(t *testing.T) {
+		timer, err := NewErrorTimer(nil)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid rule", func(t *testing.T) {
+		timer, err := NewErrorTimer(fallback, ErrorRule{Match: MatchIs(errThreshold)})
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		rule := ErrorRule{Match: MatchIs(errThreshold), Timer: fallback}
+		timer, err := NewErrorTimer(fallback, rule)
+		assert.NoError(t, err)
+		assert.NotNil(t, timer)
+		assert.Equal(t, 1, len(timer.rules))
+		assert.Equal(t, fallback, timer.fallback)
+	})
+}
+
+func TestErrorTimer_Next(t *testing.T) {
+	fallback, err := NewConstantTimer(15 * time.Second)
+	require.NoError(t, err)
+
+	maintenance, err := NewConstantTimer(5 * time.Minute)
+	require.NoError(t, err)
+
+	threshold, err := NewExponentialTimer(10*time.Second, time.Minute, 2.0, JitterNone)
+	require.NoError(t, err)
+
+	var target *maintenanceError
+	timer, err := NewErrorTimer(
+		fallback,
+		ErrorRule{Match: MatchAs(&target), Timer: maintenance},
+		ErrorRule{Match: MatchIs(errThreshold), Timer: threshold},
+	)
+	require.NoError(t, err)
+
+	t.Run("with matching rule", func(t *testing.T) {
+		assert.Equal(t, 5*time.Minute, timer.Next(&maintenanceError{}))
+		assert.Equal(t, 10*time.Second, timer.Next(errThreshold))
+		assert.Equal(t, 20*time.Second, timer.Next(errThreshold))
+	})
+
+	t.Run("with predicate rule", func(t *testing.T) {
+		var predicate Matcher = func(err error) bool {
+			return err != nil && err.Error() == "upstream"
+		}
+		timer, err := NewErrorTimer(fallback, ErrorRule{Match: predicate, Timer: maintenance})
+		require.NoError(t, err)
+
+		assert.Equal(t, 5*time.Minute, timer.Next(errors.New("upstream")))
+	})
+
+	t.Run("without matching rule", func(t *testing.T) {
+		assert.Equal(t, 15*time.Second, timer.Next(errors.New("another")))
+		assert.Equal(t, 15*time.Second, timer.Next(nil))
+	})
+}
+
+func TestErrorTimer_Reset(t *testing.T) {
+	fallback, err := NewExponentialTimer(15*time.Second, time.Minute, 2.0, JitterNone)
+	require.NoError(t, err)
+
+	threshold, err := NewExponentialTimer(10*time.Second, time.Minute, 2.0, JitterNone)
+	require.NoError(t, err)
+
+	timer, err := NewErrorTimer(fallback, ErrorRule{Match: MatchIs(errThreshold), Timer: threshold})
+	require.NoError(t, err)
+
+	timer.Next(errThreshold)
+	timer.Next(nil)
+	timer.Reset()
+
+	assert.Equal(t, 10*time.Second, timer.Next(errThreshold))
+	assert.Equal(t, 15*time.Second, timer.Next(nil))
+}
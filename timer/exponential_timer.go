@@ -0,0 +1,152 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package timer
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Jitter is a randomization strategy for the durations of ExponentialTimer
+type Jitter int8
+
+const (
+	// JitterNone returns the exact exponential durations
+	JitterNone Jitter = iota
+
+	// JitterFull returns a random duration between the initial duration and
+	// the current duration
+	JitterFull
+
+	// JitterEqual keeps the half of the current duration and randomizes the
+	// other half, the durations are never shorter than MinDuration
+	JitterEqual
+
+	// JitterDecorrelated returns a random duration between the initial
+	// duration and the multiplied value of the previously returned duration
+	JitterDecorrelated
+)
+
+// ExponentialTimer grows the duration by the multiplier on every Next call
+// until it reaches the max duration and falls back to the initial duration on
+// Reset
+type ExponentialTimer struct {
+	mutex sync.Mutex
+
+	initial, max, current, previous time.Duration
+
+	multiplier float64
+	jitter     Jitter
+	random     *rand.Rand
+}
+
+// NewExponentialTimer inits ExponentialTimer with the given options
+func NewExponentialTimer(initial, max time.Duration, multiplier float64, jitter Jitter) (*ExponentialTimer, error) {
+	if initial < MinDuration {
+		return nil, &InvalidOptionError{
+			Name: "exponential timer initial duration",
+			Type: "positive duration(greater than or equal to a millisecond)",
+		}
+	}
+
+	if max < initial {
+		return nil, &InvalidOptionError{
+			Name: "exponential timer max duration",
+			Type: "duration greater than or equal to the initial duration",
+		}
+	}
+
+	if multiplier < 1.0 {
+		return nil, &InvalidOptionError{
+			Name: "exponential timer multiplier",
+			Type: "float greater than or equal to 1.0",
+		}
+	}
+
+	if jitter < JitterNone || jitter > JitterDecorrelated {
+		return nil, &InvalidOptionError{
+			Name: "exponential timer jitter",
+			Type: "one of JitterNone, JitterFull, JitterEqual, JitterDecorrelated",
+		}
+	}
+
+	return &ExponentialTimer{
+		initial:    initial,
+		max:        max,
+		current:    initial,
+		previous:   initial,
+		multiplier: multiplier,
+		jitter:     jitter,
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Next returns the current duration with the jitter applied and multiplies the
+// current duration for the next call regardless of the error type
+func (t *ExponentialTimer) Next(_ error) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var duration time.Duration
+	switch t.jitter {
+	case JitterFull:
+		duration = t.initial + t.randomDuration(t.current-t.initial)
+	case JitterEqual:
+		duration = t.current/2 + t.randomDuration(t.current-t.current/2)
+		if duration < MinDuration {
+			duration = MinDuration
+		}
+	case JitterDecorrelated:
+		upper := t.multiply(t.previous)
+		duration = t.initial + t.randomDuration(upper-t.initial)
+		t.previous = duration
+	default:
+		duration = t.current
+	}
+
+	t.current = t.multiply(t.current)
+	return duration
+}
+
+// Reset sets the current duration to the initial duration
+func (t *ExponentialTimer) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.current = t.initial
+	t.previous = t.initial
+}
+
+// Min returns the shortest duration of the timer, the equal jitter can halve
+// the initial duration
+func (t *ExponentialTimer) Min() time.Duration {
+	if t.jitter != JitterEqual {
+		return t.initial
+	}
+	if half := t.initial / 2; half > MinDuration {
+		return half
+	}
+	return MinDuration
+}
+
+// multiply returns the multiplied duration capped with the max duration
+func (t *ExponentialTimer) multiply(d time.Duration) time.Duration {
+	next := time.Duration(float64(d) * t.multiplier)
+
+	// Protect against overflows as well as the max duration
+	if next > t.max || next < d {
+		return t.max
+	}
+	return next
+}
+
+// randomDuration returns a random duration in [0, d) range
+func (t *ExponentialTimer) randomDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(t.random.Int63n(int64(d)))
+}
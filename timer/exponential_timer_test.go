@@ -0,0 +1,173 @@
+package timer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExponentialTimer(t *testing.T) {
+	t.Run("with invalid initial duration", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Microsecond, time.Minute, 2.0, JitterNone)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid max duration", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Second, 2.0, JitterNone)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid multiplier", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 0.5, JitterNone)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid jitter", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, Jitter(42))
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, JitterFull)
+		assert.NoError(t, err)
+		assert.NotNil(t, timer)
+		assert.Equal(t, 5*time.Second, timer.initial)
+		assert.Equal(t, 5*time.Second, timer.current)
+		assert.Equal(t, time.Minute, timer.max)
+		assert.Equal(t, 2.0, timer.multiplier)
+		assert.Equal(t, JitterFull, timer.jitter)
+	})
+
+	t.Run("with sub-second durations", func(t *testing.T) {
+		timer, err := NewExponentialTimer(100*time.Millisecond, 800*time.Millisecond, 2.0, JitterNone)
+		assert.NoError(t, err)
+
+		expected := []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			800 * time.Millisecond,
+		}
+		for _, d := range expected {
+			assert.Equal(t, d, timer.Next(nil))
+		}
+	})
+}
+
+func TestExponentialTimer_Next(t *testing.T) {
+	t.Run("without jitter", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, JitterNone)
+		assert.NoError(t, err)
+
+		expected := []time.Duration{
+			5 * time.Second,
+			10 * time.Second,
+			20 * time.Second,
+			40 * time.Second,
+			time.Minute,
+			time.Minute,
+		}
+		for _, duration := range expected {
+			assert.Equal(t, duration, timer.Next(nil))
+		}
+	})
+
+	t.Run("with full jitter", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, JitterFull)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 5*time.Second, timer.Next(nil))
+
+		upper := timer.current
+		for i := 0; i < 10; i++ {
+			duration := timer.Next(nil)
+			assert.True(t, duration >= 5*time.Second)
+			assert.True(t, duration < upper)
+			upper = timer.current
+		}
+	})
+
+	t.Run("with equal jitter", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, JitterEqual)
+		assert.NoError(t, err)
+
+		current := 5 * time.Second
+		for i := 0; i < 10; i++ {
+			duration := timer.Next(nil)
+			assert.True(t, duration >= current/2)
+			assert.True(t, duration < current)
+			current = timer.current
+		}
+	})
+
+	t.Run("with jitters never shorter than the lower bounds", func(t *testing.T) {
+		for _, jitter := range []Jitter{JitterFull, JitterEqual, JitterDecorrelated} {
+			timer, err := NewExponentialTimer(MinDuration, time.Second, 1.5, jitter)
+			assert.NoError(t, err)
+
+			for i := 0; i < 1000; i++ {
+				if i%10 == 0 {
+					timer.Reset()
+				}
+				assert.GreaterOrEqual(t, int64(timer.Next(nil)), int64(MinDuration))
+			}
+		}
+	})
+
+	t.Run("with decorrelated jitter", func(t *testing.T) {
+		timer, err := NewExponentialTimer(5*time.Second, time.Minute, 3.0, JitterDecorrelated)
+		assert.NoError(t, err)
+
+		previous := 5 * time.Second
+		for i := 0; i < 10; i++ {
+			duration := timer.Next(nil)
+			assert.True(t, duration >= 5*time.Second)
+			assert.True(t, duration <= time.Minute)
+			assert.True(t, duration <= 3*previous)
+			previous = duration
+		}
+	})
+}
+
+func TestExponentialTimer_Reset(t *testing.T) {
+	timer, err := NewExponentialTimer(5*time.Second, time.Minute, 2.0, JitterNone)
+	assert.NoError(t, err)
+
+	timer.Next(nil)
+	timer.Next(nil)
+	assert.Equal(t, 20*time.Second, timer.current)
+
+	timer.Reset()
+	assert.Equal(t, 5*time.Second, timer.current)
+	assert.Equal(t, 5*time.Second, timer.Next(nil))
+}
+
+func TestExponentialTimer_Min(t *testing.T) {
+	tests := []struct {
+		initial time.Duration
+		jitter  Jitter
+		want    time.Duration
+	}{
+		{5 * time.Second, JitterNone, 5 * time.Second},
+		{5 * time.Second, JitterFull, 5 * time.Second},
+		{5 * time.Second, JitterEqual, 2500 * time.Millisecond},
+		{MinDuration, JitterEqual, MinDuration},
+		{5 * time.Second, JitterDecorrelated, 5 * time.Second},
+	}
+
+	for _, test := range tests {
+		timer, err := NewExponentialTimer(test.initial, time.Minute, 2.0, test.jitter)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, timer.Min())
+	}
+}
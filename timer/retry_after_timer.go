@@ -0,0 +1,77 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryAfter is an interface for the errors carrying a server-provided back-off
+// hint like the 'Retry-After' header of HTTP 429 and 503 responses
+type RetryAfter interface {
+	error
+
+	// RetryAfter returns the duration to wait before retrying
+	RetryAfter() time.Duration
+}
+
+// RetryAfterTimer honours the back-off hints of the errors implementing
+// RetryAfter interface and uses the fallback timer for the other errors
+type RetryAfterTimer struct {
+	min, max time.Duration
+	fallback Timer
+}
+
+// NewRetryAfterTimer inits RetryAfterTimer with the given options, the hints
+// are clamped between the min and max durations
+func NewRetryAfterTimer(min, max time.Duration, fallback Timer) (*RetryAfterTimer, error) {
+	if min < MinDuration {
+		return nil, &InvalidOptionError{
+			Name: "retry after timer min duration",
+			Type: "positive duration(greater than or equal to a millisecond)",
+		}
+	}
+
+	if max < min {
+		return nil, &InvalidOptionError{
+			Name: "retry after timer max duration",
+			Type: "duration greater than or equal to the min duration",
+		}
+	}
+
+	if fallback == nil {
+		return nil, &InvalidOptionError{
+			Name: "retry after timer fallback",
+			Type: "non-nil timer",
+		}
+	}
+
+	return &RetryAfterTimer{min: min, max: max, fallback: fallback}, nil
+}
+
+// Next returns the clamped back-off hint if the error or any error in its
+// chain implements RetryAfter, otherwise returns the duration from the
+// fallback timer
+func (t *RetryAfterTimer) Next(err error) time.Duration {
+	var hint RetryAfter
+	if !errors.As(err, &hint) {
+		return t.fallback.Next(err)
+	}
+
+	duration := hint.RetryAfter()
+	if duration < t.min {
+		return t.min
+	}
+	if duration > t.max {
+		return t.max
+	}
+	return duration
+}
+
+// Reset resets the fallback timer
+func (t *RetryAfterTimer) Reset() {
+	t.fallback.Reset()
+}
@@ -0,0 +1,101 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tooManyRequestsError struct {
+	after time.Duration
+}
+
+func (e *tooManyRequestsError) Error() string {
+	return "too many requests"
+}
+
+func (e *tooManyRequestsError) RetryAfter() time.Duration {
+	return e.after
+}
+
+func TestNewRetryAfterTimer(t *testing.T) {
+	fallback, err := NewConstantTimer(15 * time.Second)
+	require.NoError(t, err)
+
+	t.Run("with invalid min duration", func(t *testing.T) {
+		timer, err := NewRetryAfterTimer(time.Microsecond, time.Minute, fallback)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with invalid max duration", func(t *testing.T) {
+		timer, err := NewRetryAfterTimer(time.Minute, time.Second, fallback)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with nil fallback", func(t *testing.T) {
+		timer, err := NewRetryAfterTimer(time.Second, time.Minute, nil)
+		assert.Nil(t, timer)
+		assert.Error(t, err)
+		assert.IsType(t, &InvalidOptionError{}, err)
+	})
+
+	t.Run("with valid options", func(t *testing.T) {
+		timer, err := NewRetryAfterTimer(time.Second, time.Minute, fallback)
+		assert.NoError(t, err)
+		assert.NotNil(t, timer)
+		assert.Equal(t, time.Second, timer.min)
+		assert.Equal(t, time.Minute, timer.max)
+		assert.Equal(t, fallback, timer.fallback)
+	})
+}
+
+func TestRetryAfterTimer_Next(t *testing.T) {
+	fallback, err := NewExponentialTimer(15*time.Second, time.Hour, 2.0, JitterNone)
+	require.NoError(t, err)
+
+	timer, err := NewRetryAfterTimer(2*time.Second, time.Minute, fallback)
+	require.NoError(t, err)
+
+	t.Run("with hint", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, timer.Next(&tooManyRequestsError{after: 30 * time.Second}))
+	})
+
+	t.Run("with wrapped hint", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", &tooManyRequestsError{after: 10 * time.Second})
+		assert.Equal(t, 10*time.Second, timer.Next(err))
+	})
+
+	t.Run("with hint less than min duration", func(t *testing.T) {
+		assert.Equal(t, 2*time.Second, timer.Next(&tooManyRequestsError{}))
+	})
+
+	t.Run("with hint greater than max duration", func(t *testing.T) {
+		assert.Equal(t, time.Minute, timer.Next(&tooManyRequestsError{after: time.Hour}))
+	})
+
+	t.Run("without hint", func(t *testing.T) {
+		assert.Equal(t, 15*time.Second, timer.Next(errors.New("another")))
+		assert.Equal(t, 30*time.Second, timer.Next(nil))
+	})
+}
+
+func TestRetryAfterTimer_Reset(t *testing.T) {
+	fallback, err := NewExponentialTimer(15*time.Second, time.Hour, 2.0, JitterNone)
+	require.NoError(t, err)
+
+	timer, err := NewRetryAfterTimer(time.Second, time.Minute, fallback)
+	require.NoError(t, err)
+
+	timer.Next(nil)
+	timer.Reset()
+
+	assert.Equal(t, 15*time.Second, timer.Next(nil))
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package timer
+
+import "time"
+
+// MinDuration is the finest reset duration of the timers, the shorter
+// durations would reset the circuit breakers before the probes can complete
+const MinDuration = time.Millisecond
+
+// Timer is an interface to build reset durations, it is identical to the
+// shift.Timer interface so the timers of this package can be composed with any
+// shift.Timer implementation
+type Timer interface {
+	// Next returns the current duration and sets the next duration according to
+	// the given error
+	Next(error) time.Duration
+
+	// Reset resets the current duration to the initial duration
+	Reset()
+}
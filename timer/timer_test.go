@@ -0,0 +1,11 @@
+package timer
+
+import "testing"
+
+func TestTimer(t *testing.T) {
+	// Ensure the timers implement Timer on build
+	var _ Timer = (*ConstantTimer)(nil)
+	var _ Timer = (*ExponentialTimer)(nil)
+	var _ Timer = (*ErrorTimer)(nil)
+	var _ Timer = (*RetryAfterTimer)(nil)
+}
@@ -9,4 +9,19 @@ import (
 func TestTimer(t *testing.T) {
 	// Ensure ConstantTimer implements Timer on build
 	var _ Timer = (*timer.ConstantTimer)(nil)
+
+	// Ensure ExponentialTimer implements Timer on build
+	var _ Timer = (*timer.ExponentialTimer)(nil)
+
+	// Ensure ErrorTimer implements Timer on build
+	var _ Timer = (*timer.ErrorTimer)(nil)
+
+	// Ensure RetryAfterTimer implements Timer on build
+	var _ Timer = (*timer.RetryAfterTimer)(nil)
+}
+
+func TestBoundedTimer(t *testing.T) {
+	// Ensure the timers with fixed lower bounds implement BoundedTimer on build
+	var _ BoundedTimer = (*timer.ConstantTimer)(nil)
+	var _ BoundedTimer = (*timer.ExponentialTimer)(nil)
 }
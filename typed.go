@@ -0,0 +1,65 @@
+// Copyright 2020 Mustafa Turan. All rights reserved.
+// Use of this source code is governed by a Apache License 2.0 license that can
+// be found in the LICENSE file.
+
+package shift
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedOperator is a type-safe interface for circuit breaker operations
+type TypedOperator[T any] interface {
+	Execute(context.Context) (T, error)
+}
+
+// TypedOperate is a type-safe function that runs the operation
+type TypedOperate[T any] func(context.Context) (T, error)
+
+// Execute implements TypedOperator interface for any TypedOperate function for
+// free
+func (o TypedOperate[T]) Execute(ctx context.Context) (T, error) {
+	return o(ctx)
+}
+
+// TypedOnSuccess is a type-safe function to run on any successful invocation,
+// it implements SuccessHandler and skips the results of the other types
+type TypedOnSuccess[T any] func(context.Context, T)
+
+// Handle implements SuccessHandler for TypedOnSuccess func
+func (fn TypedOnSuccess[T]) Handle(ctx context.Context, res interface{}) {
+	typed, ok := res.(T)
+	if !ok && res != nil {
+		return
+	}
+	fn(ctx, typed)
+}
+
+// RunTyped executes the given typed operator with the circuit breaker and
+// returns the typed result, the result is the zero value of the type when the
+// operator is not executed or timed out. A successful result of another type,
+// e.g. from a fallback, returns ResultTypeMismatchError.
+func RunTyped[T any](ctx context.Context, s *Shift, o TypedOperator[T]) (T, error) {
+	var op Operate = func(ctx context.Context) (interface{}, error) {
+		return o.Execute(ctx)
+	}
+
+	res, err := s.Run(ctx, op)
+	typed, ok := res.(T)
+	if !ok && res != nil && err == nil {
+		return typed, &ResultTypeMismatchError{
+			Expected: reflect.TypeOf((*T)(nil)).Elem().String(),
+			Actual:   fmt.Sprintf("%T", res),
+		}
+	}
+	return typed, err
+}
+
+// Do executes the given func with the circuit breaker and returns the typed
+// result, the result is the zero value of the type when the func is not
+// executed or timed out
+func Do[T any](ctx context.Context, s *Shift, fn func(context.Context) (T, error)) (T, error) {
+	return RunTyped[T](ctx, s, TypedOperate[T](fn))
+}
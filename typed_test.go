@@ -0,0 +1,178 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name string
+}
+
+func TestTypedOperate(t *testing.T) {
+	// Ensure TypedOperate implements TypedOperator on build
+	var _ TypedOperator[string] = (TypedOperate[string])(nil)
+
+	var fn TypedOperate[string] = func(context.Context) (string, error) {
+		return "test", nil
+	}
+
+	res, err := fn.Execute(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "test", res)
+}
+
+func TestTypedOnSuccess(t *testing.T) {
+	// Ensure TypedOnSuccess implements SuccessHandler on build
+	var _ SuccessHandler = (TypedOnSuccess[string])(nil)
+
+	t.Run("with matching type", func(t *testing.T) {
+		var got string
+		var fn TypedOnSuccess[string] = func(_ context.Context, res string) {
+			got = res
+		}
+
+		fn.Handle(context.Background(), "test")
+		assert.Equal(t, "test", got)
+	})
+
+	t.Run("with nil result", func(t *testing.T) {
+		var called bool
+		var fn TypedOnSuccess[error] = func(_ context.Context, res error) {
+			assert.Nil(t, res)
+			called = true
+		}
+
+		fn.Handle(context.Background(), nil)
+		assert.True(t, called)
+	})
+
+	t.Run("with another type", func(t *testing.T) {
+		var called bool
+		var fn TypedOnSuccess[string] = func(context.Context, string) {
+			called = true
+		}
+
+		fn.Handle(context.Background(), 42)
+		assert.False(t, called)
+	})
+}
+
+func TestNilTypedOnSuccess(t *testing.T) {
+	var fn TypedOnSuccess[string]
+	assert.Panics(t, func() { fn.Handle(context.Background(), "test") })
+}
+
+func TestRunTyped(t *testing.T) {
+	var called bool
+	var handler TypedOnSuccess[*user] = func(_ context.Context, u *user) {
+		assert.Equal(t, "gopher", u.Name)
+		called = true
+	}
+
+	s, err := New(name, WithSuccessHandlers(StateClose, handler))
+	require.NoError(t, err)
+
+	t.Run("on success", func(t *testing.T) {
+		var o TypedOperate[*user] = func(context.Context) (*user, error) {
+			return &user{Name: "gopher"}, nil
+		}
+
+		u, err := RunTyped[*user](context.Background(), s, o)
+		assert.NoError(t, err)
+		assert.Equal(t, "gopher", u.Name)
+		assert.True(t, called)
+	})
+
+	t.Run("on failure", func(t *testing.T) {
+		var o TypedOperate[*user] = func(context.Context) (*user, error) {
+			return nil, errors.New("failed")
+		}
+
+		u, err := RunTyped[*user](context.Background(), s, o)
+		assert.Error(t, err)
+		assert.IsType(t, &InvocationError{}, err)
+		assert.Nil(t, u)
+	})
+
+	t.Run("on fallback", func(t *testing.T) {
+		var o TypedOperate[*user] = func(context.Context) (*user, error) {
+			return nil, errors.New("failed")
+		}
+
+		tests := []struct {
+			name     string
+			fallback FallbackFunc
+			want     *user
+			wantErr  error
+		}{
+			{
+				name: "with the same type",
+				fallback: func(context.Context, error) (interface{}, error) {
+					return &user{Name: "cached"}, nil
+				},
+				want: &user{Name: "cached"},
+			},
+			{
+				name: "with nil result",
+				fallback: func(context.Context, error) (interface{}, error) {
+					return nil, nil
+				},
+			},
+			{
+				name: "with another type",
+				fallback: func(context.Context, error) (interface{}, error) {
+					return "cached", nil
+				},
+				wantErr: &ResultTypeMismatchError{Expected: "*shift.user", Actual: "string"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				s, err := New(name, WithFallback(test.fallback))
+				require.NoError(t, err)
+
+				u, err := RunTyped[*user](context.Background(), s, o)
+				assert.Equal(t, test.wantErr, err)
+				assert.Equal(t, test.want, u)
+			})
+		}
+	})
+}
+
+func TestDo(t *testing.T) {
+	s, err := New(name)
+	require.NoError(t, err)
+
+	t.Run("on success", func(t *testing.T) {
+		res, err := Do(context.Background(), s, func(context.Context) (string, error) {
+			return "welldone", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "welldone", res)
+	})
+
+	t.Run("on failure", func(t *testing.T) {
+		res, err := Do(context.Background(), s, func(context.Context) (int, error) {
+			return 42, errors.New("failed")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 42, res)
+	})
+
+	t.Run("on open state", func(t *testing.T) {
+		s, err := New(name, WithInitialState(StateOpen))
+		require.NoError(t, err)
+
+		res, err := Do(context.Background(), s, func(context.Context) (string, error) {
+			return "welldone", nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, "", res)
+	})
+}